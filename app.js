@@ -20,9 +20,12 @@ function initializePlayground() {
     const resultOutput = document.getElementById('result-output');
     const executeBtn = document.getElementById('execute-btn');
     const shareBtn = document.getElementById('share-btn');
+    const jsonViewBtn = document.getElementById('json-view-btn');
     const copyResultBtn = document.getElementById('copy-result-btn');
     const clearResultBtn = document.getElementById('clear-result-btn');
     const clearXmlBtn = document.getElementById('clear-xml-btn');
+    const formatXmlBtn = document.getElementById('format-xml-btn');
+    const minifyXmlBtn = document.getElementById('minify-xml-btn');
     const exampleCategory = document.getElementById('example-category');
     const clearHistoryBtn = document.getElementById('clear-history-btn');
 
@@ -45,6 +48,11 @@ function initializePlayground() {
     eventManager.add(executeBtn, 'click', runQuery);
     console.log('Execute button handler registered');
     eventManager.add(shareBtn, 'click', shareQuery);
+    eventManager.add(jsonViewBtn, 'click', () => {
+        const open = jsonViewBtn.getAttribute('aria-pressed') !== 'true';
+        jsonViewBtn.setAttribute('aria-pressed', String(open));
+        runQuery();
+    });
     eventManager.add(copyResultBtn, 'click', copyResult);
     eventManager.add(clearResultBtn, 'click', () => {
         resultOutput.value = '';
@@ -57,6 +65,8 @@ function initializePlayground() {
         resultOutput.className = '';
         clearMetrics();
     });
+    eventManager.add(formatXmlBtn, 'click', () => formatXML(window.prettifyXML));
+    eventManager.add(minifyXmlBtn, 'click', () => formatXML(window.minifyXML));
     eventManager.add(exampleCategory, 'change', populateExamples);
     eventManager.add(clearHistoryBtn, 'click', clearHistory);
 
@@ -84,9 +94,13 @@ function initializePlayground() {
     }
 
     eventManager.add(xmlInput, 'input', runQueryDebounced);
-    eventManager.add(pathInput, 'input', runQueryDebounced);
+    eventManager.add(pathInput, 'input', () => {
+        updatePathSuggestions();
+        runQueryDebounced();
+    });
 
-    console.log('XMLDOT version:', window.getVersion());
+    const { version, playgroundCommit, buildDate } = window.getVersion();
+    console.log('XMLDOT version:', [version, playgroundCommit.slice(0, 12), buildDate].filter(Boolean).join(' '));
 }
 
 // ========================================
@@ -350,7 +364,9 @@ async function runQuery() {
 
         // Execute query
         console.log('About to execute WASM query...');
-        const result = window.executeQuery(xml, path);
+        // Serializing the subtree is extra work, so only ask for it while the JSON view is on
+        const jsonView = document.getElementById('json-view-btn').getAttribute('aria-pressed') === 'true';
+        const result = window.executeQuery(xml, path, jsonView ? { asJSON: true } : undefined);
         console.log('WASM query result:', result);
         const endTime = performance.now();
         const executionTime = (endTime - startTime).toFixed(2);
@@ -364,10 +380,12 @@ async function runQuery() {
             showMetrics(executionTime, 0, result.index || 0, true);
 
             // Provide helpful hints for common errors
-            if (result.error.includes('timeout')) {
+            if (result.code === 'timeout') {
                 resultOutput.value += '\n\nTip: Try simplifying your query or reducing the XML document size.';
-            } else if (result.error.includes('too large')) {
+            } else if (result.code === 'tooLarge') {
                 resultOutput.value += '\n\nTip: The playground has resource limits. Consider breaking your query into smaller parts.';
+            } else if (path.startsWith('/')) {
+                resultOutput.value += '\n\n' + xpathTip(path);
             }
             return;
         }
@@ -377,17 +395,62 @@ async function runQuery() {
             `Value: ${result.value}`,
             `Type: ${result.type}`,
             `Exists: ${result.exists}`,
+            ...(result.empty ? ['Empty: true (element has no content)'] : []),
             `Index: ${result.index}`,
+            ...(result.cdata ? ['CDATA: true'] : []),
+            ...(result.truncated ? [`Truncated: true (only the first ${result.matchLimit} matches are returned; more may exist)`] : []),
             ``,
             `Raw:`,
             result.raw || '(empty)'
-        ].join('\n');
+        ];
+
+        // Show descendant text when it differs from the element's own value
+        if (result.text !== undefined && result.text !== String(result.value)) {
+            output.push('', 'Text (all descendants):', result.text || '(empty)');
+        }
+
+        // List attributes of element results in source order
+        if (Array.isArray(result.attributeList) && result.attributeList.length > 0) {
+            output.push('', 'Attributes:');
+            for (const { name, value } of result.attributeList) {
+                output.push(`  @${name} = ${value}`);
+            }
+        }
+
+        // Show where a located element sits in the document
+        if (result.parent !== undefined) {
+            output.push('', `Parent: ${result.parent}`);
+        }
+        if (Array.isArray(result.children) && result.children.length > 0) {
+            output.push('', `Children (${result.children.length}):`);
+            for (const { path } of result.children) {
+                output.push(`  ${path}`);
+            }
+        }
+
+        // Show the matched element subtree as JSON
+        if (result.json) {
+            output.push('', 'JSON:', JSON.stringify(JSON.parse(result.json), null, 2));
+        }
 
-        resultOutput.value = output;
+        // List individual matches for array results
+        if (Array.isArray(result.results)) {
+            output.push('', `Matches (${result.results.length}):`);
+            result.results.forEach((item, i) => {
+                output.push(`  [${item.key ?? i}] ${item.value}`);
+            });
+        }
+
+        // XMLDOT paths never start with '/', so this is likely XPath
+        if (!result.exists && path.startsWith('/')) {
+            output.push('', xpathTip(path));
+        }
+
+        resultOutput.value = output.join('\n');
         resultOutput.className = 'result-success';
 
         // Calculate and display performance metrics
-        const resultSize = result.raw ? result.raw.length : result.value.length;
+        const resultSize = result.raw ? result.raw.length : String(result.value).length;
         showMetrics(executionTime, resultSize, result.index, false);
 
         // Save to history and update URL
@@ -402,6 +465,15 @@ async function runQuery() {
     }
 }
 
+// Suggest the XMLDOT equivalent of an XPath query, or explain why there is none
+function xpathTip(path) {
+    const translated = window.xpathToQuery(path);
+    if (translated.error) {
+        return `Tip: This looks like XPath, which XMLDOT cannot translate: ${translated.error}`;
+    }
+    return `Tip: This looks like XPath. The XMLDOT equivalent is: ${translated.path}`;
+}
+
 // Copy result to clipboard
 async function copyResult() {
     const resultOutput = document.getElementById('result-output');
@@ -431,6 +503,49 @@ async function copyResult() {
     }
 }
 
+// Offer next-segment completions for the query path from the document
+function updatePathSuggestions() {
+    const xml = document.getElementById('xml-input').value;
+    const path = document.getElementById('path-input').value;
+    const datalist = document.getElementById('path-suggestions');
+
+    datalist.replaceChildren();
+    if (!xml.trim() || typeof window.suggestPaths !== 'function') {
+        return;
+    }
+
+    const response = window.suggestPaths(xml, path);
+    if (response.error) {
+        return;
+    }
+
+    // Suggestions complete the last segment; offer them as full paths
+    const base = path.slice(0, path.lastIndexOf('.') + 1);
+    for (const name of response.suggestions) {
+        const option = document.createElement('option');
+        option.value = base + name;
+        datalist.appendChild(option);
+    }
+}
+
+// Reformat the XML document with a WASM formatter (prettifyXML or minifyXML)
+function formatXML(formatter) {
+    const xmlInput = document.getElementById('xml-input');
+    if (!xmlInput.value.trim()) {
+        showToast('No XML to format', 'error');
+        return;
+    }
+
+    const formatted = formatter(xmlInput.value);
+    if (formatted.error) {
+        showToast(formatted.error, 'error');
+        return;
+    }
+
+    xmlInput.value = formatted.result;
+    runQuery();
+}
+
 // Populate examples
 function populateExamples() {
     const examplesList = document.getElementById('examples-list');
@@ -762,8 +877,12 @@ function loadFromURL() {
             return;
         }
 
-        if (!window.validateXML || !window.validateXML(state.xml)) {
-            showToast('Shared XML is invalid', 'error');
+        const validation = window.validateXMLDetailed(state.xml);
+        if (!validation.valid) {
+            const detail = validation.message
+                ? ` (line ${validation.line}, column ${validation.column}: ${validation.message})`
+                : '';
+            showToast(`Shared XML is invalid${detail}`, 'error');
             return;
         }
 
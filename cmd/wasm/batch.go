@@ -0,0 +1,87 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+)
+
+// executeQueries executes several queries against the same document in one
+// call, saving the per-call conversion of the document from JavaScript.
+// Each path is validated on its own, so an invalid path yields an error
+// entry without failing the others.
+// Args: xml (string), paths (array of strings), options (optional object, as
+// for executeQuery; metrics is not reported per path)
+// Returns: array of executeQuery results in input order OR error field
+func executeQueries(this js.Value, args []js.Value) (result any) {
+	// Panic recovery with safe error return
+	defer func() {
+		if r := recover(); r != nil {
+			result = makeError(codeInternal, "Query execution failed due to resource limits or invalid input")
+		}
+	}()
+
+	// Validate argument count
+	if len(args) != 2 && len(args) != 3 {
+		return makeError(codeInvalidArgument, "Expected 2 or 3 arguments: xml, paths and optional options")
+	}
+
+	if args[0].Type() != js.TypeString {
+		return makeError(codeInvalidArgument, "First argument (xml) must be a string")
+	}
+	xml := args[0].String()
+	if xmlLen := len(xml); xmlLen > xmlSizeLimit {
+		return makeError(codeTooLarge, fmt.Sprintf("XML too large (%d bytes, max %d)", xmlLen, xmlSizeLimit))
+	}
+	if err := encodingError(trimBOM(xml)); err != nil {
+		return encodingResponse(err)
+	}
+
+	paths := args[1]
+	if !js.Global().Get("Array").Call("isArray", paths).Bool() {
+		return makeError(codeInvalidArgument, "Second argument (paths) must be an array")
+	}
+	count := paths.Length()
+	if count > MaxBatchQueries {
+		return makeError(codeTooLarge, fmt.Sprintf("Too many paths (%d, max %d)", count, MaxBatchQueries))
+	}
+
+	config := defaultQueryConfig()
+	if len(args) == 3 {
+		var err error
+		if config, err = parseQueryConfig(args[2]); err != nil {
+			return errorResponse(err)
+		}
+	}
+
+	// The time budget covers the whole batch: once it expires, the paths
+	// not evaluated yet fail with a timeout error and the others keep their
+	// results
+	results := make([]any, count)
+	failed := runWithTimeout(config.timeout, func() map[string]any {
+		for i := 0; i < count; i++ {
+			pathArg := paths.Index(i)
+			if pathArg.Type() != js.TypeString {
+				results[i] = makeError(codeInvalidArgument, fmt.Sprintf("Path at index %d must be a string", i))
+				continue
+			}
+
+			path, errResult := checkPath(pathArg.String())
+			if errResult != nil {
+				results[i] = errResult
+				continue
+			}
+			if err := checkDeadline(); err != nil {
+				results[i] = errorResponse(err)
+				continue
+			}
+			results[i] = runQuery(xml, path, config)
+		}
+		return nil
+	})
+	if failed != nil {
+		return failed
+	}
+	return results
+}
@@ -0,0 +1,93 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"container/list"
+	"maps"
+)
+
+// queryCache keeps the responses of recent executeQuery calls, so the
+// playground's live query, which reruns on every keystroke, does not
+// evaluate the same path against the same document twice. It holds the
+// results of one document at a time, whose text it keeps for comparison: a
+// call with any other document empties it. Within a document it keeps the
+// MaxCachedQueries most recently used paths, per combination of options
+// that can change the response. Error responses, timeouts included, are
+// never cached.
+type queryCache struct {
+	document string
+	entries  map[queryCacheKey]*list.Element
+	order    *list.List // most recently used first; values are *queryCacheEntry
+}
+
+// queryCacheKey identifies a query on the cached document. The timeout and
+// metrics options do not change a successful response and are left out.
+type queryCacheKey struct {
+	path             string
+	caseSensitive    bool
+	normalizeSpace   bool
+	asJSON           bool
+	strict           bool
+	expandReferences bool
+}
+
+type queryCacheEntry struct {
+	key      queryCacheKey
+	response map[string]any
+}
+
+// queries is the cache used by executeQuery. The WASM module serves calls
+// one at a time, so it needs no locking.
+var queries = &queryCache{
+	entries: make(map[queryCacheKey]*list.Element),
+	order:   list.New(),
+}
+
+// cacheKey returns the key of a query, switching the cache to xml first
+// when it holds another document.
+func (c *queryCache) cacheKey(xml, path string, config queryConfig) queryCacheKey {
+	if xml != c.document {
+		c.document = xml
+		clear(c.entries)
+		c.order.Init()
+	}
+	return queryCacheKey{
+		path:             path,
+		caseSensitive:    config.opts.CaseSensitive,
+		normalizeSpace:   config.normalizeSpace,
+		asJSON:           config.asJSON,
+		strict:           config.strict,
+		expandReferences: config.expandReferences,
+	}
+}
+
+// get returns a copy of the cached response for key, so the caller may add
+// fields to it, and marks it as recently used.
+func (c *queryCache) get(key queryCacheKey) (map[string]any, bool) {
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return maps.Clone(element.Value.(*queryCacheEntry).response), true
+}
+
+// put stores a copy of a successful response for key, evicting the least
+// recently used entry when the cache is full.
+func (c *queryCache) put(key queryCacheKey, response map[string]any) {
+	if _, failed := response["error"]; failed {
+		return
+	}
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*queryCacheEntry).response = maps.Clone(response)
+		c.order.MoveToFront(element)
+		return
+	}
+	if c.order.Len() >= MaxCachedQueries {
+		oldest := c.order.Back()
+		delete(c.entries, oldest.Value.(*queryCacheEntry).key)
+		c.order.Remove(oldest)
+	}
+	c.entries[key] = c.order.PushFront(&queryCacheEntry{key: key, response: maps.Clone(response)})
+}
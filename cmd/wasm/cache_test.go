@@ -0,0 +1,69 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"syscall/js"
+	"testing"
+)
+
+// largeConfig has 50 devices of 10 interfaces each.
+var largeConfig = func() string {
+	var doc strings.Builder
+	doc.WriteString("<devices>")
+	for d := 0; d < 50; d++ {
+		fmt.Fprintf(&doc, "<device><hostname>r%d</hostname><interfaces>", d)
+		for i := 0; i < 10; i++ {
+			fmt.Fprintf(&doc, "<interface><name>Gi0/%d</name><mtu>1500</mtu></interface>", i)
+		}
+		doc.WriteString("</interfaces></device>")
+	}
+	doc.WriteString("</devices>")
+	return doc.String()
+}()
+
+// BenchmarkDescendantQuery compares a "**" query run cold, with the
+// document's outline already parsed by an earlier query, and answered from
+// the query cache.
+func BenchmarkDescendantQuery(b *testing.B) {
+	args := []js.Value{js.ValueOf(largeConfig), js.ValueOf("**.interface[2].name")}
+	b.Run("cold", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			resetCaches()
+			executeQuery(js.Undefined(), args)
+		}
+	})
+	b.Run("warm outline", func(b *testing.B) {
+		resetCaches()
+		executeQuery(js.Undefined(), args)
+		for i := 0; i < b.N; i++ {
+			queries.cacheKey("", "", defaultQueryConfig())
+			executeQuery(js.Undefined(), args)
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		resetCaches()
+		for i := 0; i < b.N; i++ {
+			executeQuery(js.Undefined(), args)
+		}
+	})
+}
+
+// There is no race test: the race detector is not supported on js/wasm, and
+// the module serves one JavaScript call at a time, so its package state
+// (queries, parsedOutline, evaluationDeadline) is not shared between
+// concurrent calls. What is shared between calls is the cache, and the
+// responses it returns must not alias its entries.
+func TestCachedResponsesAreCopies(t *testing.T) {
+	resetCaches()
+	first := mustQuery(t, smallConfig, "config.system.hostname")
+	first["value"] = "changed"
+	delete(first, "path")
+
+	second := mustQuery(t, smallConfig, "config.system.hostname")
+	if second["value"] != "r1" || second["path"] != "config.system.hostname" {
+		t.Errorf("cached response changed with the caller's copy: %v", second)
+	}
+}
@@ -0,0 +1,202 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"syscall/js"
+)
+
+// canonicalizeXML writes a document in a canonical form, so documents that
+// differ only in ways XML gives no meaning to compare and hash as equal. It
+// is a pragmatic subset of Canonical XML 1.0 (C14N) without comments:
+//   - the XML declaration, the DOCTYPE and comments are dropped
+//   - attributes are written in double quotes, namespace declarations first,
+//     each group sorted by name
+//   - empty elements are written as a start and end tag pair
+//   - character and entity references are expanded, CDATA sections become
+//     text, and text and attribute values are escaped as C14N does
+//   - line breaks are normalized to LF
+//   - processing instructions are kept, those outside the document element
+//     on lines of their own
+//
+// Unlike C14N, whitespace-only text is dropped from elements without other
+// text, unless xml:space="preserve" is in scope, so indentation does not
+// count. Attributes are sorted by their names as written rather than by
+// namespace URI and local name, and namespace declarations are kept where
+// they are written, even when redundant, so documents that only rename
+// prefixes or move declarations canonicalize differently.
+// Args: xml (string)
+// Returns: map with result field OR error field
+func canonicalizeXML(this js.Value, args []js.Value) (result any) {
+	// Panic recovery with safe error return
+	defer func() {
+		if r := recover(); r != nil {
+			result = makeError(codeInternal, "Canonicalization failed due to resource limits or invalid input")
+		}
+	}()
+
+	// Validate argument count
+	if len(args) != 1 {
+		return makeError(codeInvalidArgument, "Expected 1 argument: xml")
+	}
+
+	doc, errResult := formatArg(args[0])
+	if errResult != nil {
+		return errResult
+	}
+	masked, entities, _ := readDoctype(doc)
+
+	tokens, mixed, err := canonicalTokens(masked, entities)
+	if err != nil {
+		return makeError(codeMalformed, "Invalid XML document: "+err.Error())
+	}
+
+	var out strings.Builder
+	depth, rootWritten := 0, false
+	preserve := []bool{false}
+	for i, token := range tokens {
+		switch t := token.(type) {
+		case xml.StartElement:
+			space := preserve[len(preserve)-1]
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xml" && attr.Name.Local == "space" {
+					space = attr.Value == "preserve"
+				}
+			}
+			preserve = append(preserve, space)
+			writeCanonicalStart(&out, t)
+			depth++
+		case xml.EndElement:
+			out.WriteString("</" + qualifiedName(t.Name) + ">")
+			preserve = preserve[:len(preserve)-1]
+			if depth--; depth == 0 {
+				rootWritten = true
+			}
+		case xml.CharData:
+			if depth == 0 || (strings.TrimSpace(string(t)) == "" && !mixed[i] && !preserve[len(preserve)-1]) {
+				continue
+			}
+			escapeCanonical(&out, string(t), false)
+		case xml.ProcInst:
+			if strings.EqualFold(t.Target, "xml") {
+				continue
+			}
+			if depth == 0 && rootWritten {
+				out.WriteByte('\n')
+			}
+			out.WriteString("<?" + t.Target)
+			if inst := strings.TrimLeft(string(t.Inst), " \t\r\n"); inst != "" {
+				out.WriteString(" " + inst)
+			}
+			out.WriteString("?>")
+			if depth == 0 && !rootWritten {
+				out.WriteByte('\n')
+			}
+		}
+	}
+	return mutationResult(out.String())
+}
+
+// canonicalTokens decodes doc, expanding the given entities, and returns
+// its elements, text and processing instructions. mixed marks the text
+// tokens whose element also has text other than whitespace, where
+// whitespace-only text is content rather than indentation.
+func canonicalTokens(doc string, entities map[string]string) ([]xml.Token, map[int]bool, error) {
+	decoder := xml.NewDecoder(strings.NewReader(doc))
+	decoder.Entity = entities
+	decoder.CharsetReader = passThroughCharset
+
+	var tokens []xml.Token
+	mixed := make(map[int]bool)
+	var open [][]int // indexes of the text tokens of each open element
+	hasText := []bool{false}
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			return tokens, mixed, nil
+		}
+		if err != nil {
+			var syntaxErr *xml.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				err = errors.New(syntaxErr.Msg)
+			}
+			return nil, nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			open = append(open, nil)
+			hasText = append(hasText, false)
+		case xml.EndElement:
+			if len(open) == 0 {
+				return nil, nil, errors.New("unexpected end element")
+			}
+			if hasText[len(hasText)-1] {
+				for _, i := range open[len(open)-1] {
+					mixed[i] = true
+				}
+			}
+			open, hasText = open[:len(open)-1], hasText[:len(hasText)-1]
+		case xml.CharData:
+			if len(open) > 0 {
+				open[len(open)-1] = append(open[len(open)-1], len(tokens))
+				hasText[len(hasText)-1] = hasText[len(hasText)-1] || strings.TrimSpace(string(t)) != ""
+			}
+		case xml.Comment, xml.Directive:
+			continue
+		}
+		tokens = append(tokens, xml.CopyToken(token))
+	}
+}
+
+// writeCanonicalStart writes a start tag with namespace declarations first
+// and each group of attributes sorted by name.
+func writeCanonicalStart(out *strings.Builder, start xml.StartElement) {
+	attrs := append([]xml.Attr(nil), start.Attr...)
+	sort.SliceStable(attrs, func(i, j int) bool {
+		declI, declJ := isNamespaceDecl(attrs[i].Name), isNamespaceDecl(attrs[j].Name)
+		if declI != declJ {
+			return declI
+		}
+		return qualifiedName(attrs[i].Name) < qualifiedName(attrs[j].Name)
+	})
+
+	out.WriteString("<" + qualifiedName(start.Name))
+	for _, attr := range attrs {
+		out.WriteString(" " + qualifiedName(attr.Name) + `="`)
+		escapeCanonical(out, attr.Value, true)
+		out.WriteByte('"')
+	}
+	out.WriteByte('>')
+}
+
+// escapeCanonical writes text or an attribute value with the escapes of
+// C14N: '&', '<' and '>' in text and '&', '<', '"', tab, LF and CR in
+// attribute values, and CR in text.
+func escapeCanonical(out *strings.Builder, s string, attribute bool) {
+	for _, r := range s {
+		switch {
+		case r == '&':
+			out.WriteString("&amp;")
+		case r == '<':
+			out.WriteString("&lt;")
+		case r == '>' && !attribute:
+			out.WriteString("&gt;")
+		case r == '"' && attribute:
+			out.WriteString("&quot;")
+		case r == '\t' && attribute:
+			out.WriteString("&#x9;")
+		case r == '\n' && attribute:
+			out.WriteString("&#xA;")
+		case r == '\r':
+			out.WriteString("&#xD;")
+		default:
+			out.WriteRune(r)
+		}
+	}
+}
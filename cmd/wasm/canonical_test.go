@@ -0,0 +1,42 @@
+//go:build js && wasm
+
+package main
+
+import "testing"
+
+func TestCanonicalizeXML(t *testing.T) {
+	tests := []struct {
+		name, xml, want string
+	}{
+		{"declaration, DOCTYPE and comments", `<?xml version="1.0"?><!DOCTYPE r><!-- c --><r><!-- c --><a/></r>`, `<r><a></a></r>`},
+		{"attributes", `<r b='2' xmlns:y="u2" a="1" xmlns="u1"/>`, `<r xmlns="u1" xmlns:y="u2" a="1" b="2"></r>`},
+		{"references and CDATA", `<!DOCTYPE r [<!ENTITY e "x">]><r a="&#65;&quot;">&e;&#66;<![CDATA[<&>]]></r>`, `<r a="A&quot;">xB&lt;&amp;&gt;</r>`},
+		{"attribute whitespace", "<r a=\"&#9;&#10;&#13;\"/>", `<r a="&#x9;&#xA;&#xD;"></r>`},
+		{"line breaks", "<r>a\r\nb\rc</r>", "<r>a\nb\nc</r>"},
+		{"indentation", "<r>\n  <a>1</a>\n  <b> 2 </b>\n</r>", `<r><a>1</a><b> 2 </b></r>`},
+		{"mixed content", "<r>x <a>1</a> y</r>", "<r>x <a>1</a> y</r>"},
+		{"xml:space", "<r xml:space=\"preserve\">\n  <a>1</a>\n</r>", "<r xml:space=\"preserve\">\n  <a>1</a>\n</r>"},
+		{"processing instructions", `<?pi  a?><r><?pi?></r><?pi b?>`, "<?pi a?>\n<r><?pi?></r>\n<?pi b?>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mustResult(t, canonicalizeXML, tt.xml); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeXMLEquivalentDocuments(t *testing.T) {
+	a := mustResult(t, canonicalizeXML, "<?xml version=\"1.0\"?>\n<r b='2' a=\"1\">\n  <x/>\n</r>")
+	b := mustResult(t, canonicalizeXML, `<r a="1" b="2"><x></x></r>`)
+	if a != b {
+		t.Errorf("%q and %q differ", a, b)
+	}
+}
+
+func TestCanonicalizeXMLRejectsMalformed(t *testing.T) {
+	if response := call(t, canonicalizeXML, "<r><a></r>"); response["code"] != codeMalformed {
+		t.Errorf("got %v, want a malformed error", response)
+	}
+}
@@ -0,0 +1,70 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"strings"
+
+	"github.com/netascode/xmldot"
+)
+
+const (
+	cdataStart = "<![CDATA["
+	cdataEnd   = "]]>"
+)
+
+// hasCDATA reports whether the content of n contains a CDATA section.
+func (n *node) hasCDATA(doc string) bool {
+	return strings.Contains(doc[n.innerStart:n.innerEnd], cdataStart)
+}
+
+// cdataText returns the text of element content that contains CDATA.
+// Content made only of CDATA sections (and whitespace between them) is
+// returned verbatim with adjacent sections concatenated, so entities stay
+// untouched and a "]]>" split across sections is reassembled.
+// Mixed content returns all character data in document order with entities
+// outside CDATA expanded and surrounding whitespace trimmed.
+func cdataText(inner string) string {
+	if text, ok := cdataOnly(inner); ok {
+		return text
+	}
+	return charData(inner)
+}
+
+// cdataItems fixes the values of the Element matches of an Array result
+// whose content contains CDATA. xmldot returns their raw content intact but
+// mis-scans their values, so the text is read from Raw instead.
+func cdataItems(result xmldot.Result) xmldot.Result {
+	items := make([]xmldot.Result, len(result.Results))
+	for i, item := range result.Results {
+		if item.Type == xmldot.Element && strings.Contains(item.Raw, cdataStart) {
+			item.Str = cdataText(item.Raw)
+		}
+		items[i] = item
+	}
+	result.Results = items
+	return result
+}
+
+// cdataOnly concatenates the CDATA sections of content consisting solely of
+// CDATA sections and whitespace. It reports false for anything else.
+func cdataOnly(inner string) (string, bool) {
+	var text strings.Builder
+	rest := strings.TrimSpace(inner)
+	if rest == "" {
+		return "", false
+	}
+
+	for rest != "" {
+		if !strings.HasPrefix(rest, cdataStart) {
+			return "", false
+		}
+		body, after, found := strings.Cut(rest[len(cdataStart):], cdataEnd)
+		if !found {
+			return "", false
+		}
+		text.WriteString(body)
+		rest = strings.TrimSpace(after)
+	}
+	return text.String(), true
+}
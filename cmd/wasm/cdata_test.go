@@ -0,0 +1,58 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"syscall/js"
+	"testing"
+)
+
+// splitScript is a script as serializers write it into XML: the "]]>" of
+// "a[b[0]]>" cannot occur in a CDATA section, so the section is closed
+// after "]]" and a new one starts with ">".
+const splitScript = `<config>` +
+	`<script name="check"><![CDATA[if (a[b[0]]]]><![CDATA[> 1) { return "<ok>"; }]]></script>` +
+	`<script name="noop"><![CDATA[]]></script>` +
+	`</config>`
+
+const script = `if (a[b[0]]> 1) { return "<ok>"; }`
+
+func TestSplitCDATAIsReassembled(t *testing.T) {
+	for _, path := range []string{"config.script", "config.script.0", "config.script.#(@name==check)"} {
+		response := mustQuery(t, splitScript, path)
+		if response["value"] != script || response["cdata"] != true {
+			t.Errorf("%s: got %q (cdata %v), want %q", path, response["value"], response["cdata"], script)
+		}
+	}
+}
+
+func TestSplitCDATAInArrayMatches(t *testing.T) {
+	for _, path := range []string{"config.*", "**.script"} {
+		results, _ := mustQuery(t, splitScript, path)["results"].([]any)
+		if len(results) != 2 {
+			t.Fatalf("%s: got %d results, want 2", path, len(results))
+		}
+		for i, want := range []string{script, ""} {
+			if value := results[i].(map[string]any)["value"]; value != want {
+				t.Errorf("%s: result %d = %q, want %q", path, i, value, want)
+			}
+		}
+	}
+}
+
+func TestSplitCDATAIsValid(t *testing.T) {
+	if valid := validateXML(js.Undefined(), []js.Value{js.ValueOf(splitScript)}); valid != true {
+		t.Errorf("validateXML = %v, want true", valid)
+	}
+	if detailed := call(t, validateXMLDetailed, splitScript); detailed["valid"] != true {
+		t.Errorf("validateXMLDetailed = %v, want valid", detailed)
+	}
+}
+
+func TestIndexOnDocumentWithCDATA(t *testing.T) {
+	for _, path := range []string{"0", "5"} {
+		if response := mustQuery(t, splitScript, path); response["exists"] != false {
+			t.Errorf("%s: got %v, want no match", path, response)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"strings"
+
+	"github.com/netascode/xmldot"
+)
+
+// Boolean operators combining the conditions of a "#(...)" filter, e.g.
+// "interfaces.interface.#(enabled==true && mtu>1500)#". "&&" binds more
+// tightly than "||", so "a || b && c" reads as "a || (b && c)", and
+// parentheses group. xmldot filters take a single condition, so filters
+// using them are evaluated here, one library filter per condition.
+const (
+	conditionAnd = "&&"
+	conditionOr  = "||"
+)
+
+// condition is a parsed boolean filter condition: a single condition as
+// the library reads it (atom), or the conjunction (and) or disjunction (or)
+// of operands.
+type condition struct {
+	atom     string
+	and, or  bool
+	operands []*condition
+}
+
+// cutBooleanFilter reports whether segment is a "#(...)" or "#(...)#" filter
+// whose condition uses && or || and returns the parsed condition and
+// whether all matches are kept.
+func cutBooleanFilter(segment string) (c *condition, all, ok bool, err error) {
+	inner, ok := strings.CutPrefix(segment, "#(")
+	if !ok {
+		return nil, false, false, nil
+	}
+	if all = strings.HasSuffix(inner, ")#"); all {
+		inner = strings.TrimSuffix(inner, ")#")
+	} else if inner, ok = strings.CutSuffix(inner, ")"); !ok {
+		return nil, false, false, nil
+	}
+	if !strings.Contains(inner, conditionAnd) && !strings.Contains(inner, conditionOr) {
+		return nil, false, false, nil
+	}
+
+	p := &conditionParser{input: inner}
+	c, err = p.parseOr()
+	if err == nil && p.skipSpace() < len(p.input) {
+		err = newError(codeInvalidPath, "Unexpected %q in filter condition", p.input[p.pos:])
+	}
+	if err != nil {
+		return nil, false, false, err
+	}
+	if c.atom != "" {
+		// The operators were inside quoted values
+		return nil, false, false, nil
+	}
+	return c, all, true, nil
+}
+
+// conditionParser reads a boolean filter condition by recursive descent.
+type conditionParser struct {
+	input string
+	pos   int
+}
+
+// skipSpace advances past whitespace and returns the new position.
+func (p *conditionParser) skipSpace() int {
+	for p.pos < len(p.input) && isXMLSpace(p.input[p.pos]) {
+		p.pos++
+	}
+	return p.pos
+}
+
+// consume advances past op when it comes next.
+func (p *conditionParser) consume(op string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.input[p.pos:], op) {
+		p.pos += len(op)
+		return true
+	}
+	return false
+}
+
+// parseOr reads operands separated by ||.
+func (p *conditionParser) parseOr() (*condition, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	c := &condition{or: true, operands: []*condition{first}}
+	for p.consume(conditionOr) {
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		c.operands = append(c.operands, next)
+	}
+	if len(c.operands) == 1 {
+		return first, nil
+	}
+	return c, nil
+}
+
+// parseAnd reads operands separated by &&.
+func (p *conditionParser) parseAnd() (*condition, error) {
+	first, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	c := &condition{and: true, operands: []*condition{first}}
+	for p.consume(conditionAnd) {
+		next, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		c.operands = append(c.operands, next)
+	}
+	if len(c.operands) == 1 {
+		return first, nil
+	}
+	return c, nil
+}
+
+// parseOperand reads a parenthesized condition or a single condition, which
+// runs up to the next operator or unmatched ')' outside quotes.
+func (p *conditionParser) parseOperand() (*condition, error) {
+	if p.consume("(") {
+		c, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")") {
+			return nil, newError(codeInvalidPath, "Missing ')' in filter condition")
+		}
+		return c, nil
+	}
+
+	start, depth := p.skipSpace(), 0
+scan:
+	for ; p.pos < len(p.input); p.pos++ {
+		rest := p.input[p.pos:]
+		switch c := rest[0]; {
+		case c == '\\':
+			p.pos++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(rest[1:], c)
+			if end < 0 {
+				return nil, newError(codeInvalidPath, "Unterminated string in filter condition")
+			}
+			p.pos += end + 1
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth == 0 {
+				break scan
+			}
+			depth--
+		case depth == 0 && (strings.HasPrefix(rest, conditionAnd) || strings.HasPrefix(rest, conditionOr)):
+			break scan
+		}
+	}
+	atom := strings.TrimSpace(p.input[start:min(p.pos, len(p.input))])
+	if atom == "" {
+		return nil, newError(codeInvalidPath, "Empty condition in filter")
+	}
+	return &condition{atom: atom}, nil
+}
+
+// matches reports whether the element n satisfies c. Each single condition
+// is run by the library as a filter on the element alone, or through
+// filterByText when it tests the element's own text, so it compares exactly
+// as the same condition does in a "#(...)" filter of its own.
+func (c *condition) matches(doc string, n *node, opts *xmldot.Options) bool {
+	switch {
+	case c.and:
+		for _, operand := range c.operands {
+			if !operand.matches(doc, n, opts) {
+				return false
+			}
+		}
+		return true
+	case c.or:
+		for _, operand := range c.operands {
+			if operand.matches(doc, n, opts) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if self, normalize, _, ok := cutSelfFilter("#(" + c.atom + ")"); ok {
+		return len(filterByText(doc, []*node{n}, self, normalize)) > 0
+	}
+	return xmldot.GetWithOptions(doc[n.start:n.end], escapeSegment(n.name)+".#("+c.atom+")", opts).Exists()
+}
+
+// filterByCondition keeps the nodes satisfying c, in document order.
+func filterByCondition(doc string, nodes []*node, c *condition, opts *xmldot.Options) []*node {
+	var result []*node
+	for _, n := range nodes {
+		if c.matches(doc, n, opts) {
+			result = append(result, n)
+		}
+	}
+	return result
+}
@@ -0,0 +1,64 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+const interfaces = `<interfaces>` +
+	`<interface><name>a</name><enabled>true</enabled><mtu>9000</mtu></interface>` +
+	`<interface><name>b</name><enabled>false</enabled><mtu>9000</mtu></interface>` +
+	`<interface><name>c</name><enabled>true</enabled><mtu>1500</mtu></interface>` +
+	`<interface><name>d&amp;e</name><enabled>false</enabled><mtu>1500</mtu></interface>` +
+	`</interfaces>`
+
+func TestBooleanFilters(t *testing.T) {
+	tests := []struct {
+		condition string
+		names     []any
+	}{
+		{"enabled==true && mtu>1500", []any{"a"}},
+		{"enabled==true || mtu>1500", []any{"a", "b", "c"}},
+		{"name==d&e || name==a", []any{"a", "d&e"}},
+		// && binds more tightly than ||
+		{"name==b || enabled==true && mtu<9000", []any{"b", "c"}},
+		{"(name==b || enabled==true) && mtu<9000", []any{"c"}},
+		{`name=="d&&e" || name==a`, []any{"a"}},
+		{"mtu==1 && name==a", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			response := mustQuery(t, interfaces, "interfaces.interface.#("+tt.condition+")#.name")
+			// Like the library's filters, a single match is not an array
+			var names []any
+			if results, ok := response["results"].([]any); ok {
+				for _, item := range results {
+					names = append(names, item.(map[string]any)["value"])
+				}
+			} else if response["exists"] == true {
+				names = append(names, response["value"])
+			}
+			if !reflect.DeepEqual(names, tt.names) {
+				t.Errorf("names = %v, want %v", names, tt.names)
+			}
+		})
+	}
+}
+
+func TestBooleanFilterFirstMatch(t *testing.T) {
+	response := mustQuery(t, interfaces, "interfaces.interface.#(enabled==false && mtu<9000).name")
+	if response["value"] != "d&e" {
+		t.Errorf("value = %v, want d&e", response["value"])
+	}
+}
+
+func TestBooleanFilterErrors(t *testing.T) {
+	for _, condition := range []string{"mtu>1 &&", "(mtu>1 || name==a", `name=="a && mtu>1`} {
+		response := call(t, executeQuery, interfaces, "interfaces.interface.#("+condition+")#")
+		if response["code"] != codeInvalidPath {
+			t.Errorf("%s: got %v, want an invalidPath error", condition, response)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"syscall/js"
+)
+
+// xmlDeclaration reads the XML declaration that opens a document, for the
+// playground's document details. Documents without a declaration are not an
+// error: version and encoding are empty and standalone is null.
+// Args: xml (string)
+// Returns: map with declared, version, encoding and standalone (boolean or
+// null) fields OR error field
+func xmlDeclaration(this js.Value, args []js.Value) (result any) {
+	// Panic recovery with safe error return
+	defer func() {
+		if r := recover(); r != nil {
+			result = makeError(codeInternal, "Failed to read XML declaration")
+		}
+	}()
+
+	// Validate argument count
+	if len(args) != 1 {
+		return makeError(codeInvalidArgument, "Expected 1 argument: xml")
+	}
+	if args[0].Type() != js.TypeString {
+		return makeError(codeInvalidArgument, "First argument (xml) must be a string")
+	}
+
+	xml := args[0].String()
+	if xmlLen := len(xml); xmlLen > xmlSizeLimit {
+		return makeError(codeTooLarge, fmt.Sprintf("XML too large (%d bytes, max %d)", xmlLen, xmlSizeLimit))
+	}
+
+	response := map[string]any{
+		"declared":   false,
+		"version":    "",
+		"encoding":   "",
+		"standalone": nil,
+	}
+	pseudoAttrs, ok := declarationAttrs(trimBOM(xml))
+	if !ok {
+		return response
+	}
+
+	response["declared"] = true
+	response["version"] = pseudoAttrs["version"]
+	response["encoding"] = pseudoAttrs["encoding"]
+	switch pseudoAttrs["standalone"] {
+	case "yes":
+		response["standalone"] = true
+	case "no":
+		response["standalone"] = false
+	}
+	return response
+}
+
+// declarationAttrs returns the pseudo-attributes of the XML declaration at
+// the very start of doc, which is the only place one may appear. It reports
+// false when doc does not start with a complete declaration.
+func declarationAttrs(doc string) (map[string]string, bool) {
+	rest, ok := strings.CutPrefix(doc, "<?xml")
+	if !ok || rest == "" || !strings.ContainsRune(" \t\r\n", rune(rest[0])) {
+		return nil, false
+	}
+	end := strings.Index(rest, "?>")
+	if end < 0 {
+		return nil, false
+	}
+
+	// Read the pseudo-attributes as attributes of a start tag
+	tag := "<xml" + rest[:end] + ">"
+	attrs := make(map[string]string)
+	for _, token := range attributeTokens(tag) {
+		_, value, _ := strings.Cut(tag[token.start:token.end], "=")
+		value = strings.TrimSpace(value)
+		attrs[token.name] = value[1 : len(value)-1]
+	}
+	return attrs, true
+}
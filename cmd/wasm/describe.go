@@ -0,0 +1,94 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/netascode/xmldot"
+)
+
+// describeNode summarizes the structure of one element, for the playground's
+// schema explorer: its attributes, the names of its child elements with how
+// often each occurs, and whether it has text of its own. The path may use
+// any query syntax but must select a single element.
+// Args: xml (string), path (string)
+// Returns: map with exists (false when nothing matches; the other fields are
+// then omitted), tag (the name as written, prefix included), path (the
+// element's canonical path), attributes (array of {name, value} in source
+// order), children (array of {name, count}, one per distinct child name in
+// order of first occurrence, so repeated children are counted rather than
+// listed) and hasText (non-whitespace character data directly inside the
+// element, CDATA included) fields OR error field
+func describeNode(this js.Value, args []js.Value) (result any) {
+	// Panic recovery with safe error return
+	defer func() {
+		if r := recover(); r != nil {
+			result = makeError(codeInternal, "Failed to describe element due to resource limits or invalid input")
+		}
+	}()
+
+	// Validate argument count
+	if len(args) != 2 {
+		return makeError(codeInvalidArgument, "Expected 2 arguments: xml and path")
+	}
+
+	source, path, errResult := queryArgs(args[0], args[1])
+	if errResult != nil {
+		return errResult
+	}
+	if isMultipath(path) {
+		return makeError(codeInvalidPath, "Multipath queries cannot be described; describe each path on its own")
+	}
+
+	xml, _, doctypeErr := readDoctype(source)
+	if doctypeErr != nil {
+		return makeError(codeMalformed, "Invalid DOCTYPE declaration: "+doctypeErr.Message)
+	}
+
+	eval, err := evaluate(xml, path, defaultQueryConfig().opts)
+	if err != nil {
+		return errorResponse(err)
+	}
+	switch {
+	case !eval.result.Exists():
+		return map[string]any{"exists": false}
+	case eval.result.IsArray():
+		return makeError(codeInvalidPath, fmt.Sprintf("Path matches %d results; select one element with an index or position", len(eval.result.Results)))
+	case eval.result.Type != xmldot.Element:
+		return makeError(codeInvalidPath, "Path selects a value, not an element")
+	}
+
+	element := eval.element
+	if element == nil && len(eval.matches) == 1 {
+		element = eval.matches[0]
+	}
+	if element == nil {
+		return makeError(codeInternal, "Matched element could not be located in the document")
+	}
+
+	var children []any
+	counts := make(map[string]map[string]any)
+	for _, child := range element.children {
+		if entry, ok := counts[child.name]; ok {
+			entry["count"] = entry["count"].(int) + 1
+			continue
+		}
+		entry := map[string]any{"name": child.name, "count": 1}
+		counts[child.name] = entry
+		children = append(children, entry)
+	}
+	if children == nil {
+		children = []any{}
+	}
+
+	return map[string]any{
+		"exists":     true,
+		"tag":        element.name,
+		"path":       element.canonicalPath(),
+		"attributes": element.attributeList(),
+		"children":   children,
+		"hasText":    len(textNodes(xml[element.innerStart:element.innerEnd])) > 0,
+	}
+}
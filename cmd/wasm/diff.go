@@ -0,0 +1,210 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"syscall/js"
+
+	"github.com/netascode/xmldot"
+)
+
+// MaxDiffChanges caps the changes reported by diffXML.
+const MaxDiffChanges = 1000
+
+// diffXML compares two documents structurally, for reviewing configuration
+// changes without the noise of a text diff. Elements are matched by name:
+// the nth child with a given name in one document is compared with the nth
+// child with that name in the other, so the order of differently named
+// siblings and of attributes is ignored. Comments and processing
+// instructions are not compared.
+// Args: a (string), b (string), options (optional object:
+// significantWhitespace (boolean, default false) compares text exactly
+// instead of trimming and collapsing whitespace; whitespace-only text
+// between child elements is always ignored)
+// Returns: map with equal, changes (array of {type: "added", "removed" or
+// "modified", kind: "element", "attribute" or "text", path, oldValue,
+// newValue}, grouped by element with added elements last; paths select the
+// node in b, or in a for removals, and element values are the element's
+// markup) and truncated (more than MaxDiffChanges changes) fields OR error
+// field
+func diffXML(this js.Value, args []js.Value) (result any) {
+	// Panic recovery with safe error return
+	defer func() {
+		if r := recover(); r != nil {
+			result = makeError(codeInternal, "Diff failed due to resource limits or invalid input")
+		}
+	}()
+
+	// Validate argument count
+	if len(args) != 2 && len(args) != 3 {
+		return makeError(codeInvalidArgument, "Expected 2 or 3 arguments: a, b and optional options")
+	}
+
+	docs := make([]string, 2)
+	for i, name := range []string{"First argument (a)", "Second argument (b)"} {
+		if args[i].Type() != js.TypeString {
+			return makeError(codeInvalidArgument, name+" must be a string")
+		}
+		doc := trimBOM(args[i].String())
+		if docLen := len(doc); docLen > xmlSizeLimit {
+			return makeError(codeTooLarge, fmt.Sprintf("%s too large (%d bytes, max %d)", name, docLen, xmlSizeLimit))
+		}
+		if masked, _, err := readDoctype(doc); err != nil || !xmldot.Valid(masked) {
+			return makeError(codeMalformed, name+" is not a valid XML document")
+		}
+		if err := encodingError(doc); err != nil {
+			return makeError(codeMalformed, name+" is not a valid XML document: "+err.Message)
+		}
+		docs[i] = doc
+	}
+
+	d := &differ{a: docs[0], b: docs[1]}
+	if len(args) == 3 && !args[2].IsUndefined() && !args[2].IsNull() {
+		if args[2].Type() != js.TypeObject {
+			return makeError(codeInvalidArgument, "Third argument (options) must be an object")
+		}
+		if significant := args[2].Get("significantWhitespace"); !significant.IsUndefined() {
+			if significant.Type() != js.TypeBoolean {
+				return makeError(codeInvalidArgument, "Option significantWhitespace must be a boolean")
+			}
+			d.significantWhitespace = significant.Bool()
+		}
+	}
+
+	rootA, err := parseOutline(d.a)
+	if err != nil {
+		return makeError(codeMalformed, "First argument (a) is not a valid XML document")
+	}
+	rootB, err := parseOutline(d.b)
+	if err != nil {
+		return makeError(codeMalformed, "Second argument (b) is not a valid XML document")
+	}
+	d.compareChildren(rootA, rootB)
+
+	return map[string]any{
+		"equal":     len(d.changes) == 0,
+		"changes":   d.changes,
+		"truncated": d.truncated,
+	}
+}
+
+// differ collects the changes between documents a and b.
+type differ struct {
+	a, b                  string
+	significantWhitespace bool
+	changes               []any
+	truncated             bool
+}
+
+// add records a change, or marks the diff truncated once MaxDiffChanges is
+// reached. Empty values are omitted.
+func (d *differ) add(changeType, kind, path, oldValue, newValue string) {
+	if len(d.changes) == MaxDiffChanges {
+		d.truncated = true
+		return
+	}
+	change := map[string]any{
+		"type": changeType,
+		"kind": kind,
+		"path": path,
+	}
+	if changeType != "added" {
+		change["oldValue"] = oldValue
+	}
+	if changeType != "removed" {
+		change["newValue"] = newValue
+	}
+	d.changes = append(d.changes, change)
+}
+
+// compareElements compares two elements with the same name.
+func (d *differ) compareElements(a, b *node) {
+	path := b.canonicalPath()
+
+	attrsA, attrsB := a.attributeMap(), b.attributeMap()
+	for _, attr := range a.attrs {
+		name := qualifiedName(attr.Name)
+		segment := ".@" + escapeSegment(name)
+		switch newValue, ok := attrsB[name]; {
+		case !ok:
+			d.add("removed", "attribute", a.canonicalPath()+segment, attr.Value, "")
+		case newValue != attr.Value:
+			d.add("modified", "attribute", path+segment, attr.Value, newValue.(string))
+		}
+	}
+	for _, attr := range b.attrs {
+		if name := qualifiedName(attr.Name); attrsA[name] == nil {
+			d.add("added", "attribute", path+".@"+escapeSegment(name), "", attr.Value)
+		}
+	}
+
+	textA, textB := d.text(d.a, a), d.text(d.b, b)
+	if textA != textB {
+		switch {
+		case textA == "":
+			d.add("added", "text", path, "", textB)
+		case textB == "":
+			d.add("removed", "text", a.canonicalPath(), textA, "")
+		default:
+			d.add("modified", "text", path, textA, textB)
+		}
+	}
+
+	d.compareChildren(a, b)
+}
+
+// compareChildren pairs the children of a and b by name and occurrence and
+// compares each pair. Unpaired children are reported as removed or added.
+func (d *differ) compareChildren(a, b *node) {
+	seen := make(map[string]int)
+	for _, child := range a.children {
+		matches := childrenWithName(b, child.name)
+		if i := seen[child.name]; i < len(matches) {
+			d.compareElements(child, matches[i])
+		} else {
+			d.add("removed", "element", child.canonicalPath(), d.a[child.start:child.end], "")
+		}
+		seen[child.name]++
+	}
+
+	added := make(map[string]int)
+	for _, child := range b.children {
+		if added[child.name]++; added[child.name] > len(childrenWithName(a, child.name)) {
+			d.add("added", "element", child.canonicalPath(), "", d.b[child.start:child.end])
+		}
+	}
+}
+
+// text returns the character data directly inside n, excluding that of its
+// child elements, with whitespace normalized unless it is significant.
+// Whitespace-only text is always reported as empty.
+func (d *differ) text(doc string, n *node) string {
+	var text strings.Builder
+	offset := n.innerStart
+	for _, child := range n.children {
+		text.WriteString(descendantText(doc[offset:child.start]))
+		offset = child.end
+	}
+	text.WriteString(descendantText(doc[offset:n.innerEnd]))
+
+	if strings.TrimSpace(text.String()) == "" {
+		return ""
+	}
+	if d.significantWhitespace {
+		return text.String()
+	}
+	return normalizeSpace(text.String())
+}
+
+// childrenWithName returns the children of n named exactly name.
+func childrenWithName(n *node, name string) []*node {
+	var matches []*node
+	for _, child := range n.children {
+		if child.name == name {
+			matches = append(matches, child)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,51 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffXML(t *testing.T) {
+	a := `<r v="1" gone="x"><i><n>a</n></i><i><n>b</n></i><t>  some   text </t><old/></r>`
+	b := "<r gone2=\"y\" v=\"2\">\n  <t>some text</t>\n  <i><n>a</n></i>\n  <i><n>c</n></i>\n  <i><n>d</n></i>\n</r>"
+	want := []any{
+		map[string]any{"type": "modified", "kind": "attribute", "path": "r.@v", "oldValue": "1", "newValue": "2"},
+		map[string]any{"type": "removed", "kind": "attribute", "path": "r.@gone", "oldValue": "x"},
+		map[string]any{"type": "added", "kind": "attribute", "path": "r.@gone2", "newValue": "y"},
+		map[string]any{"type": "modified", "kind": "text", "path": "r.i.1.n", "oldValue": "b", "newValue": "c"},
+		map[string]any{"type": "removed", "kind": "element", "path": "r.old", "oldValue": "<old/>"},
+		map[string]any{"type": "added", "kind": "element", "path": "r.i.2", "newValue": "<i><n>d</n></i>"},
+	}
+	response := call(t, diffXML, a, b)
+	if response["equal"] != false || response["truncated"] != false {
+		t.Errorf("equal = %v, truncated = %v, want false and false", response["equal"], response["truncated"])
+	}
+	if !reflect.DeepEqual(response["changes"], want) {
+		t.Errorf("changes = %v\nwant %v", response["changes"], want)
+	}
+}
+
+func TestDiffXMLIgnoresFormatting(t *testing.T) {
+	response := call(t, diffXML, `<r b="2" a='1'><x>a  b</x></r>`, "<r a=\"1\" b=\"2\">\n  <x> a b </x>\n</r>")
+	if response["equal"] != true {
+		t.Errorf("got %v, want equal", response)
+	}
+}
+
+func TestDiffXMLSignificantWhitespace(t *testing.T) {
+	response := call(t, diffXML, `<r><x>a  b</x></r>`, "<r>\n  <x>a b</x>\n</r>", map[string]any{"significantWhitespace": true})
+	want := []any{map[string]any{"type": "modified", "kind": "text", "path": "r.x", "oldValue": "a  b", "newValue": "a b"}}
+	if !reflect.DeepEqual(response["changes"], want) {
+		t.Errorf("changes = %v, want %v", response["changes"], want)
+	}
+}
+
+func TestDiffXMLRejectsMalformed(t *testing.T) {
+	for _, args := range [][]any{{"<r>", "<r/>"}, {"<r/>", "<r><a></r>"}} {
+		if response := call(t, diffXML, args...); response["code"] != codeMalformed {
+			t.Errorf("%v: got %v, want a malformed error", args, response)
+		}
+	}
+}
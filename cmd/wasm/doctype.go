@@ -0,0 +1,360 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/netascode/xmldot"
+)
+
+// MaxEntityExpansion caps the replacement text of an entity declared in the
+// internal DTD subset, counted after nested references are expanded.
+const MaxEntityExpansion = 64 * 1024
+
+// predefinedEntities are the entities every XML document may reference.
+var predefinedEntities = map[string]string{
+	"lt":   "<",
+	"gt":   ">",
+	"amp":  "&",
+	"apos": "'",
+	"quot": `"`,
+}
+
+// readDoctype prepares a document with a DOCTYPE declaration for the library.
+// xmldot skips the declaration by scanning for the first ']' and '>', which
+// stops early when the internal subset has one inside a quoted literal,
+// comment or processing instruction. The subset is therefore blanked out in
+// the returned document, keeping offsets and line numbers, and its general
+// entities are returned with their replacement text. Entities whose text
+// contains markup or undeclared references are left out and stay unexpanded.
+// Entities referring to themselves, directly or not, or expanding beyond
+// MaxEntityExpansion are rejected so a small DTD cannot inflate results.
+func readDoctype(doc string) (string, map[string]string, *xmldot.ValidateError) {
+	open, err := subsetStart(doc)
+	if err != nil || open < 0 {
+		return doc, nil, err
+	}
+
+	declared := make(map[string]entityDecl)
+	closing, err := scanSubset(doc, open+1, declared)
+	if err != nil {
+		return doc, nil, err
+	}
+	end := closing + 1
+	for end < len(doc) && isXMLSpace(doc[end]) {
+		end++
+	}
+	if end == len(doc) || doc[end] != '>' {
+		return doc, nil, validateErrorAt(doc, end, "expected '>' after DOCTYPE internal subset")
+	}
+
+	masked := []byte(doc)
+	for i := open + 1; i < closing; i++ {
+		if masked[i] != '\n' && masked[i] != '\r' {
+			masked[i] = ' '
+		}
+	}
+
+	entities, err := expandDeclarations(doc, declared)
+	if err != nil {
+		return doc, nil, err
+	}
+	return string(masked), entities, nil
+}
+
+// subsetStart returns the offset of the '[' opening the internal subset of
+// the DOCTYPE declaration of doc, or -1 when there is none.
+func subsetStart(doc string) (int, *xmldot.ValidateError) {
+	start := doctypeStart(doc)
+	if start < 0 {
+		return -1, nil
+	}
+
+	// The subset follows the name and external identifier, if any
+	for i := start + len("<!DOCTYPE"); i < len(doc); i++ {
+		switch doc[i] {
+		case '"', '\'':
+			end := strings.IndexByte(doc[i+1:], doc[i])
+			if end < 0 {
+				return -1, validateErrorAt(doc, i, "unterminated literal in DOCTYPE declaration")
+			}
+			i += end + 1
+		case '[':
+			return i, nil
+		case '>':
+			return -1, nil
+		}
+	}
+	return -1, validateErrorAt(doc, start, "unterminated DOCTYPE declaration")
+}
+
+// declaredEntities returns the names of the general entities declared in
+// the internal subset of doc, whether or not readDoctype expands them.
+func declaredEntities(doc string) map[string]bool {
+	open, err := subsetStart(doc)
+	if err != nil || open < 0 {
+		return nil
+	}
+	declared := make(map[string]entityDecl)
+	if _, err := scanSubset(doc, open+1, declared); err != nil {
+		return nil
+	}
+	names := make(map[string]bool, len(declared))
+	for name := range declared {
+		names[name] = true
+	}
+	return names
+}
+
+// restoreSubset puts the internal subset that readDoctype blanked out of
+// source back into modified, a document derived from masked, as long as
+// the edit left the prolog alone.
+func restoreSubset(source, masked, modified string) string {
+	end := len(source)
+	for end > 0 && source[end-1] == masked[end-1] {
+		end--
+	}
+	if !strings.HasPrefix(modified, masked[:end]) {
+		return modified
+	}
+	return source[:end] + modified[end:]
+}
+
+// doctypeStart returns the offset of "<!DOCTYPE" in the prolog of doc, after
+// the XML declaration, comments and processing instructions, or -1.
+func doctypeStart(doc string) int {
+	i := 0
+	for {
+		for i < len(doc) && isXMLSpace(doc[i]) {
+			i++
+		}
+		rest := doc[i:]
+		switch {
+		case strings.HasPrefix(rest, "<!DOCTYPE"):
+			return i
+		case strings.HasPrefix(rest, "<!--"):
+			end := strings.Index(rest[len("<!--"):], "-->")
+			if end < 0 {
+				return -1
+			}
+			i += len("<!--") + end + len("-->")
+		case strings.HasPrefix(rest, "<?"):
+			end := strings.Index(rest, "?>")
+			if end < 0 {
+				return -1
+			}
+			i += end + len("?>")
+		default:
+			return -1
+		}
+	}
+}
+
+// entityDecl is a general entity declared in the internal subset.
+type entityDecl struct {
+	value  string // the literal as written; empty for external entities
+	offset int    // offset of "<!ENTITY" in the document
+	parsed bool   // false for external entities, which are never expanded
+}
+
+// scanSubset reads the internal subset starting at offset i, up to the ']'
+// closing it, and returns that offset. Quoted literals, comments and
+// processing instructions are skipped whole, and brackets nest so a ']'
+// inside a conditional section does not end the subset. General entity
+// declarations are added to declared; the first declaration of a name wins.
+func scanSubset(doc string, i int, declared map[string]entityDecl) (int, *xmldot.ValidateError) {
+	depth := 0
+	for i < len(doc) {
+		rest := doc[i:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			end := strings.Index(rest[len("<!--"):], "-->")
+			if end < 0 {
+				return 0, validateErrorAt(doc, i, "unterminated comment in DOCTYPE internal subset")
+			}
+			i += len("<!--") + end + len("-->")
+		case strings.HasPrefix(rest, "<?"):
+			end := strings.Index(rest, "?>")
+			if end < 0 {
+				return 0, validateErrorAt(doc, i, "unterminated processing instruction in DOCTYPE internal subset")
+			}
+			i += end + len("?>")
+		case rest[0] == '"' || rest[0] == '\'':
+			end := strings.IndexByte(rest[1:], rest[0])
+			if end < 0 {
+				return 0, validateErrorAt(doc, i, "unterminated literal in DOCTYPE internal subset")
+			}
+			i += end + 2
+		case strings.HasPrefix(rest, "<!ENTITY") && depth == 0:
+			end, err := readEntityDecl(doc, i, declared)
+			if err != nil {
+				return 0, err
+			}
+			i = end
+		case rest[0] == '[':
+			depth++
+			i++
+		case rest[0] == ']':
+			if depth == 0 {
+				return i, nil
+			}
+			depth--
+			i++
+		default:
+			i++
+		}
+	}
+	return 0, validateErrorAt(doc, len(doc), "unterminated DOCTYPE internal subset")
+}
+
+// readEntityDecl reads the entity declaration at offset start and returns
+// the offset just past it. Parameter entities are skipped: they only matter
+// inside the DTD, which is not otherwise interpreted.
+func readEntityDecl(doc string, start int, declared map[string]entityDecl) (int, *xmldot.ValidateError) {
+	var fields []string
+	i := start + len("<!ENTITY")
+	for {
+		for i < len(doc) && isXMLSpace(doc[i]) {
+			i++
+		}
+		if i == len(doc) {
+			return 0, validateErrorAt(doc, start, "unterminated entity declaration")
+		}
+		if doc[i] == '>' {
+			i++
+			break
+		}
+
+		fieldStart := i
+		if doc[i] == '"' || doc[i] == '\'' {
+			end := strings.IndexByte(doc[i+1:], doc[i])
+			if end < 0 {
+				return 0, validateErrorAt(doc, i, "unterminated literal in entity declaration")
+			}
+			i += end + 2
+		} else {
+			for i < len(doc) && !isXMLSpace(doc[i]) && doc[i] != '>' && doc[i] != '"' && doc[i] != '\'' {
+				i++
+			}
+		}
+		fields = append(fields, doc[fieldStart:i])
+	}
+
+	if len(fields) < 2 {
+		return 0, validateErrorAt(doc, start, "incomplete entity declaration")
+	}
+	if fields[0] == "%" {
+		return i, nil
+	}
+	name := fields[0]
+	if _, ok := declared[name]; ok {
+		return i, nil
+	}
+	decl := entityDecl{offset: start}
+	if literal := fields[1]; literal[0] == '"' || literal[0] == '\'' {
+		decl.value, decl.parsed = literal[1:len(literal)-1], true
+	}
+	declared[name] = decl
+	return i, nil
+}
+
+// expandDeclarations resolves the replacement text of each declared entity.
+// Expansion is memoized so chains of references take linear time, and a
+// reference back to an entity being expanded is reported as recursive.
+func expandDeclarations(doc string, declared map[string]entityDecl) (map[string]string, *xmldot.ValidateError) {
+	type expansion struct {
+		text string
+		safe bool // no markup and no unresolved references
+	}
+	expanded := make(map[string]expansion, len(declared))
+	expanding := make(map[string]bool)
+
+	var expand func(name string) (expansion, *xmldot.ValidateError)
+	expand = func(name string) (expansion, *xmldot.ValidateError) {
+		if e, ok := expanded[name]; ok {
+			return e, nil
+		}
+		decl := declared[name]
+		if expanding[name] {
+			return expansion{}, validateErrorAt(doc, decl.offset, fmt.Sprintf("entity '%s' refers to itself", name))
+		}
+		if !decl.parsed {
+			return expansion{}, nil
+		}
+		expanding[name] = true
+		defer delete(expanding, name)
+
+		var text strings.Builder
+		safe := true
+		value := decl.value
+		for value != "" {
+			amp := strings.IndexByte(value, '&')
+			if amp < 0 {
+				text.WriteString(value)
+				break
+			}
+			text.WriteString(value[:amp])
+			semi := strings.IndexByte(value[amp:], ';')
+			if semi < 0 {
+				text.WriteString(value[amp:])
+				safe = false
+				break
+			}
+			ref := value[amp+1 : amp+semi]
+			value = value[amp+semi+1:]
+
+			switch r, isChar := parseCharRef(strings.TrimPrefix(ref, "#")); {
+			case strings.HasPrefix(ref, "#") && isChar:
+				// A reference to '<' or '&' becomes markup when the entity is used
+				safe = safe && r != '<' && r != '&'
+				text.WriteRune(r)
+			case predefinedEntities[ref] != "":
+				text.WriteString(predefinedEntities[ref])
+			case declared[ref].parsed:
+				nested, err := expand(ref)
+				if err != nil {
+					return expansion{}, err
+				}
+				safe = safe && nested.safe
+				text.WriteString(nested.text)
+			default:
+				text.WriteString("&" + ref + ";")
+				safe = false
+			}
+			if text.Len() > MaxEntityExpansion {
+				return expansion{}, validateErrorAt(doc, decl.offset, fmt.Sprintf("entity '%s' expands beyond %d bytes", name, MaxEntityExpansion))
+			}
+		}
+
+		e := expansion{text: text.String(), safe: safe && !strings.Contains(decl.value, "<")}
+		expanded[name] = e
+		return e, nil
+	}
+
+	// Declaration order makes the reported error deterministic
+	names := make([]string, 0, len(declared))
+	for name := range declared {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return declared[names[i]].offset < declared[names[j]].offset })
+
+	entities := make(map[string]string)
+	for _, name := range names {
+		e, err := expand(name)
+		if err != nil {
+			return nil, err
+		}
+		if e.safe && declared[name].parsed {
+			entities[name] = e.text
+		}
+	}
+	return entities, nil
+}
+
+// isXMLSpace reports whether c is XML whitespace.
+func isXMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,60 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"strings"
+	"syscall/js"
+	"testing"
+)
+
+// laughs nests entities ten deep five times over, a billion laughs small
+// enough to show the expansion cap rather than the document size limit.
+const laughs = `<!DOCTYPE r [<!ENTITY a "aaaaaaaaaa">` +
+	`<!ENTITY b "&a;&a;&a;&a;&a;&a;&a;&a;&a;&a;">` +
+	`<!ENTITY c "&b;&b;&b;&b;&b;&b;&b;&b;&b;&b;">` +
+	`<!ENTITY d "&c;&c;&c;&c;&c;&c;&c;&c;&c;&c;">` +
+	`<!ENTITY e "&d;&d;&d;&d;&d;&d;&d;&d;&d;&d;">]><r><a>&e;</a></r>`
+
+func TestDoctypes(t *testing.T) {
+	tests := []struct {
+		name  string
+		xml   string
+		value string // of r.a
+		error string // part of the error message, when the document is rejected
+	}{
+		{name: "no DOCTYPE", xml: `<r><a>x</a></r>`, value: "x"},
+		{name: "external subset only", xml: `<!DOCTYPE r SYSTEM "r.dtd"><r><a>x</a></r>`, value: "x"},
+		{
+			name:  "internal subset with entities",
+			xml:   `<!DOCTYPE r [<!ELEMENT r ANY><!ENTITY host "r1"><!ENTITY fqdn "&host;.example.com">]><r><a>&fqdn;</a></r>`,
+			value: "r1.example.com",
+		},
+		{name: "billion laughs", xml: laughs, error: "expands beyond 65536 bytes"},
+		{name: "recursive entity", xml: `<!DOCTYPE r [<!ENTITY a "x&a;">]><r><a>&a;</a></r>`, error: "refers to itself"},
+		{name: "mutually recursive entities", xml: `<!DOCTYPE r [<!ENTITY a "&b;"><!ENTITY b "&a;">]><r><a>&a;</a></r>`, error: "refers to itself"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid := validateXML(js.Undefined(), []js.Value{js.ValueOf(tt.xml)})
+			if valid != (tt.error == "") {
+				t.Errorf("validateXML = %v", valid)
+			}
+			detailed := call(t, validateXMLDetailed, tt.xml)
+			if message, _ := detailed["message"].(string); detailed["valid"] != (tt.error == "") || !strings.Contains(message, tt.error) {
+				t.Errorf("validateXMLDetailed = %v", detailed)
+			}
+
+			response := call(t, executeQuery, tt.xml, "r.a")
+			if tt.error == "" {
+				if response["value"] != tt.value {
+					t.Errorf("executeQuery = %v, want %q", response, tt.value)
+				}
+				return
+			}
+			if message, _ := response["error"].(string); response["code"] != codeMalformed || !strings.Contains(message, tt.error) {
+				t.Errorf("executeQuery = %v, want a malformed error about %q", response, tt.error)
+			}
+		})
+	}
+}
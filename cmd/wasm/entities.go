@@ -0,0 +1,237 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/netascode/xmldot"
+)
+
+// expandReferences expands the character references and declared entities
+// written in the values of a result: the content of an element, read from
+// its Raw, or an attribute value, read from the source at eval.span. xmldot
+// decodes the five predefined entities but returns other references as
+// written, so "&#65;" and "&amp;#65;" both read "&#65;" in its values and
+// only the source tells them apart. Entities are the general entities
+// declared in the document's internal DTD subset, as returned by
+// readDoctype; other references are left as written. Values whose source
+// is not known, such as attributes of Array results and modifier output,
+// are left as the library returns them, as is content read around CDATA
+// sections, which is decoded from the source already (see getElement).
+//
+// Each entity is capped at MaxEntityExpansion, but a document may reference
+// one many times, so the expanded values of the whole result are capped at
+// the document size limit as well.
+func expandReferences(xml string, eval evaluation, entities map[string]string) (xmldot.Result, error) {
+	r := eval.result
+	if eval.cdata {
+		return r, nil
+	}
+
+	var err error
+	budget := xmlSizeLimit
+	switch r.Type {
+	case xmldot.Element:
+		if !strings.Contains(r.Raw, cdataStart) {
+			r.Str, err = expandWritten(r.Str, r.Raw, entities, &budget)
+		}
+	case xmldot.Attribute:
+		if eval.span != nil {
+			if raw, ok := attributeValue(xml[eval.span.start:eval.span.end]); ok {
+				r.Str, err = expandWritten(r.Str, raw, entities, &budget)
+			}
+		}
+	case xmldot.Array:
+		results := make([]xmldot.Result, len(r.Results))
+		for i, item := range r.Results {
+			if item.Type == xmldot.Element && !strings.Contains(item.Raw, cdataStart) {
+				if item.Str, err = expandWritten(item.Str, item.Raw, entities, &budget); err != nil {
+					break
+				}
+			}
+			results[i] = item
+		}
+		r.Results = results
+	}
+	return r, err
+}
+
+// expandWritten expands the references in value, a library value read from
+// raw, that are written as references in raw. Every '&' in value stands
+// for an "&amp;", a bare '&' or a reference other than the predefined
+// entities in raw, in the same order (see writtenReferences), so the two
+// are walked together. When they do not line up value is returned as is.
+// The length of the result is deducted from budget, and expansion fails
+// once budget is exhausted.
+func expandWritten(value, raw string, entities map[string]string, budget *int) (string, error) {
+	if !strings.Contains(value, "&") {
+		return value, nil
+	}
+	refs := writtenReferences(raw)
+	if strings.Count(value, "&") != len(refs) {
+		return value, nil
+	}
+
+	var text strings.Builder
+	for _, ref := range refs {
+		start := strings.IndexByte(value, '&')
+		text.WriteString(value[:start])
+		value = value[start:]
+		if ref == "" || !strings.HasPrefix(value, ref) {
+			text.WriteByte('&')
+			value = value[1:]
+			continue
+		}
+
+		name := ref[1 : len(ref)-1]
+		if r, ok := parseCharRef(name[1:]); ok && name[0] == '#' {
+			text.WriteRune(r)
+		} else if replacement, ok := entities[name]; ok && name[0] != '#' {
+			text.WriteString(replacement)
+		} else {
+			text.WriteString(ref)
+		}
+		value = value[len(ref):]
+		if text.Len() > *budget {
+			return "", entityBudgetError()
+		}
+	}
+	text.WriteString(value)
+	if text.Len() > *budget {
+		return "", entityBudgetError()
+	}
+	*budget -= text.Len()
+	return text.String(), nil
+}
+
+// writtenReferences lists what each '&' in the character data of raw, an
+// element's content or an attribute value as written, leaves in the
+// library's value: the reference as written for character references and
+// entities other than the predefined ones, and "" for "&amp;" and for a '&'
+// that starts no reference. The predefined entities other than "&amp;"
+// leave no '&' and are skipped, as are tags, comments, processing
+// instructions and CDATA sections.
+func writtenReferences(raw string) []string {
+	var refs []string
+	for i := 0; i < len(raw); i++ {
+		switch raw[i] {
+		case '<':
+			i = skipMarkup(raw, i) - 1
+		case '&':
+			end := strings.IndexByte(raw[i:], ';')
+			if end < 0 || !isReferenceName(raw[i+1:i+end]) {
+				refs = append(refs, "")
+				continue
+			}
+			switch ref := raw[i : i+end+1]; ref {
+			case "&amp;":
+				refs = append(refs, "")
+			case "&lt;", "&gt;", "&quot;", "&apos;":
+			default:
+				refs = append(refs, ref)
+			}
+			i += end
+		}
+	}
+	return refs
+}
+
+// isReferenceName reports whether name, the text between '&' and ';', can
+// name an entity or a character reference.
+func isReferenceName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if isXMLSpace(byte(c)) || strings.ContainsRune("&<>\"'", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// skipMarkup returns the offset just past the tag, comment, processing
+// instruction or CDATA section starting at raw[i], or len(raw) when it is
+// not closed. Quoted attribute values in tags may contain '>'.
+func skipMarkup(raw string, i int) int {
+	for _, delims := range [][2]string{{"<!--", "-->"}, {cdataStart, "]]>"}, {"<?", "?>"}} {
+		if strings.HasPrefix(raw[i:], delims[0]) {
+			if end := strings.Index(raw[i+len(delims[0]):], delims[1]); end >= 0 {
+				return i + len(delims[0]) + end + len(delims[1])
+			}
+			return len(raw)
+		}
+	}
+
+	var quote byte
+	for j := i + 1; j < len(raw); j++ {
+		switch c := raw[j]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return j + 1
+		}
+	}
+	return len(raw)
+}
+
+// attributeValue returns the value of a name="value" attribute token as
+// written, between its quotes.
+func attributeValue(token string) (string, bool) {
+	start := strings.IndexAny(token, "\"'")
+	if start < 0 || len(token) < start+2 || token[len(token)-1] != token[start] {
+		return "", false
+	}
+	return token[start+1 : len(token)-1], true
+}
+
+// expandedText returns the character data of fragment and its descendants
+// (see descendantText) with references decoded and declared entities
+// expanded. The expanded entities are counted first so a fragment
+// referencing a large entity many times is refused rather than built.
+func expandedText(fragment string, entities map[string]string) (string, error) {
+	size := len(fragment)
+	for _, ref := range writtenReferences(fragment) {
+		if ref != "" {
+			size += len(entities[ref[1:len(ref)-1]])
+		}
+	}
+	if size > xmlSizeLimit {
+		return "", entityBudgetError()
+	}
+	return descendantTextWith(fragment, entities), nil
+}
+
+// entityBudgetError reports a result whose expanded entities exceed the
+// document size limit.
+func entityBudgetError() error {
+	return newError(codeTooLarge, "Expanded entities exceed %d bytes", xmlSizeLimit)
+}
+
+// parseCharRef parses the digits of a character reference ("65" or "x41").
+func parseCharRef(ref string) (rune, bool) {
+	base := 10
+	if strings.HasPrefix(ref, "x") {
+		ref, base = ref[1:], 16
+	}
+	if ref == "" || strings.ContainsAny(ref, "+-") {
+		return 0, false
+	}
+
+	code, err := strconv.ParseUint(ref, base, 32)
+	if err != nil {
+		return 0, false
+	}
+	r := rune(code)
+	if !utf8.ValidRune(r) || (r < 0x20 && r != '\t' && r != '\n' && r != '\r') || r == 0xFFFE || r == 0xFFFF {
+		return 0, false
+	}
+	return r, true
+}
@@ -0,0 +1,55 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error codes sent in the code field of error responses, so JavaScript
+// callers can tell a mistake in their input from a resource limit without
+// matching messages.
+const (
+	codeInvalidArgument = "invalidArgument" // wrong argument count, type or option
+	codeInvalidPath     = "invalidPath"     // the query path cannot be read
+	codeMalformed       = "malformed"       // XML or JSON input is not well-formed
+	codeTooLarge        = "tooLarge"        // an input, result or count is over its limit
+	codeDepthExceeded   = "depthExceeded"   // nesting deeper than xmldot's MaxNestingDepth
+	codeTimeout         = "timeout"         // the query time budget expired
+	codeInternal        = "internal"        // evaluation failed unexpectedly
+)
+
+// codedError is an error that carries one of the error codes.
+type codedError struct {
+	code    string
+	message string
+}
+
+func (e *codedError) Error() string {
+	return e.message
+}
+
+// newError returns a codedError with a formatted message.
+func newError(code, format string, args ...any) error {
+	return &codedError{code: code, message: fmt.Sprintf(format, args...)}
+}
+
+// makeError creates a standardized error response.
+// Only includes user-safe error messages - no stack traces or internal details.
+func makeError(code, message string) map[string]any {
+	return map[string]any{
+		"error": message,
+		"code":  code,
+	}
+}
+
+// errorResponse converts err into an error response, using its code when it
+// is a codedError and codeInvalidArgument otherwise.
+func errorResponse(err error) map[string]any {
+	var coded *codedError
+	if errors.As(err, &coded) {
+		return makeError(coded.code, coded.message)
+	}
+	return makeError(codeInvalidArgument, err.Error())
+}
@@ -0,0 +1,62 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"strings"
+	"syscall/js"
+)
+
+// pathSpecialChars are the characters with a meaning in path syntax. A
+// backslash makes the next character literal, so "config\.section" names an
+// element called "config.section". Of these, only the dot can occur in an
+// XML name; the others are escaped so any string can be used as a segment.
+// "/" has no meaning in paths and is not escaped.
+const pathSpecialChars = `\.*?#@|[](){},%!=<>`
+
+// escapePath escapes a literal element or attribute name for use as a single
+// path segment, e.g. "1.2.3" becomes "1\.2\.3". Prefix "@" to the result for
+// attribute steps; the colon of a namespace prefix is kept as is.
+// Args: name (string)
+// Returns: map with segment field OR error field
+func escapePath(this js.Value, args []js.Value) (result any) {
+	// Panic recovery with safe error return
+	defer func() {
+		if r := recover(); r != nil {
+			result = makeError(codeInternal, "Failed to escape name")
+		}
+	}()
+
+	// Validate argument count
+	if len(args) != 1 {
+		return makeError(codeInvalidArgument, "Expected 1 argument: name")
+	}
+	if args[0].Type() != js.TypeString {
+		return makeError(codeInvalidArgument, "First argument (name) must be a string")
+	}
+
+	name := args[0].String()
+	if name == "" {
+		return makeError(codeInvalidArgument, "Name must not be empty")
+	}
+	segment := escapeSegment(name)
+	if len(segment) > querySizeLimit {
+		return makeError(codeTooLarge, "Escaped name exceeds the query size limit")
+	}
+	return map[string]any{
+		"segment": segment,
+	}
+}
+
+// escapeSegment backslash-escapes the path syntax characters in name; see
+// pathSpecialChars. unescapePath reverses it.
+func escapeSegment(name string) string {
+	var segment strings.Builder
+	for i := 0; i < len(name); i++ {
+		if strings.IndexByte(pathSpecialChars, name[i]) >= 0 {
+			segment.WriteByte('\\')
+		}
+		segment.WriteByte(name[i])
+	}
+	return segment.String()
+}
@@ -0,0 +1,59 @@
+//go:build js && wasm
+
+package main
+
+import "testing"
+
+func TestEscapePath(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "1.2.3", want: `1\.2\.3`},
+		{name: "a*b?c", want: `a\*b\?c`},
+		{name: "x@y#z", want: `x\@y\#z`},
+		{name: "a[1]", want: `a\[1\]`},
+		{name: `a\b`, want: `a\\b`},
+		{name: "a|b", want: `a\|b`},
+		{name: "ns:name", want: "ns:name"},
+		{name: "config/section", want: "config/section"},
+	}
+	for _, tt := range tests {
+		if got := call(t, escapePath, tt.name)["segment"]; got != tt.want {
+			t.Errorf("escapePath(%q) = %v, want %s", tt.name, got, tt.want)
+		}
+	}
+	if response := call(t, escapePath, ""); response["code"] != codeInvalidArgument {
+		t.Errorf("escapePath(\"\") = %v, want an invalidArgument error", response)
+	}
+}
+
+// dotted has element and attribute names containing dots.
+const dotted = `<r><a.b k.v="1"><v1.2.3>x</v1.2.3></a.b><a>no</a></r>`
+
+func TestEscapePathRoundTrip(t *testing.T) {
+	section := call(t, escapePath, "a.b")["segment"].(string)
+	version := call(t, escapePath, "v1.2.3")["segment"].(string)
+	key := call(t, escapePath, "k.v")["segment"].(string)
+
+	path := "r." + section + "." + version
+	response := mustQuery(t, dotted, path)
+	if response["value"] != "x" {
+		t.Errorf("%s = %q, want x", path, response["value"])
+	}
+	// Paths built by the bindings escape names the same way
+	if response["path"] != path {
+		t.Errorf("Result path = %v, want %s", response["path"], path)
+	}
+	if value := mustQuery(t, dotted, "r."+section+".@"+key)["value"]; value != "1" {
+		t.Errorf("attribute k.v = %q, want 1", value)
+	}
+	if converted := call(t, xpathToQuery, "/r/a.b/v1.2.3")["path"]; converted != path {
+		t.Errorf("xpathToQuery = %v, want %s", converted, path)
+	}
+
+	children := mustQuery(t, dotted, "r."+section)["children"].([]any)
+	if child := children[0].(map[string]any); child["name"] != "v1.2.3" || child["path"] != path {
+		t.Errorf("children[0] = %v, want v1.2.3 at %s", child, path)
+	}
+}
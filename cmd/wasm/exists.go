@@ -0,0 +1,63 @@
+//go:build js && wasm
+
+package main
+
+import "syscall/js"
+
+// pathExists reports whether a path matches anything, for quick presence
+// checks. It evaluates the path like executeQuery but builds no response:
+// matched elements are not located in the document and no values are
+// converted. xmldot has no presence-only query, so the library still reads
+// the first match.
+// Args: xml (string), path (string), options (optional object, as for
+// executeQuery; only caseSensitive, timeoutMs and strict apply)
+// Returns: map with exists field OR error field
+func pathExists(this js.Value, args []js.Value) (result any) {
+	// Panic recovery with safe error return
+	defer func() {
+		if r := recover(); r != nil {
+			result = makeError(codeInternal, "Query execution failed due to resource limits or invalid input")
+		}
+	}()
+
+	// Validate argument count
+	if len(args) != 2 && len(args) != 3 {
+		return makeError(codeInvalidArgument, "Expected 2 or 3 arguments: xml, path and optional options")
+	}
+
+	xml, path, errResult := queryArgs(args[0], args[1])
+	if errResult != nil {
+		return errResult
+	}
+	if isMultipath(path) {
+		return makeError(codeInvalidPath, "Multipath queries are not supported; check each path on its own")
+	}
+
+	config := defaultQueryConfig()
+	if len(args) == 3 {
+		var err error
+		if config, err = parseQueryConfig(args[2]); err != nil {
+			return errorResponse(err)
+		}
+	}
+
+	if config.strict {
+		if err := strictError(trimBOM(xml)); err != nil {
+			return strictResponse(err)
+		}
+	}
+	xml, _, doctypeErr := readDoctype(xml)
+	if doctypeErr != nil {
+		return makeError(codeMalformed, "Invalid DOCTYPE declaration: "+doctypeErr.Message)
+	}
+
+	return runWithTimeout(config.timeout, func() map[string]any {
+		eval, err := evaluate(xml, path, config.opts)
+		if err != nil {
+			return errorResponse(err)
+		}
+		return map[string]any{
+			"exists": eval.result.Exists(),
+		}
+	})
+}
@@ -0,0 +1,307 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall/js"
+
+	"github.com/netascode/xmldot"
+)
+
+// explainQuery describes how a path is read, segment by segment, for the
+// playground's syntax breakdown. It checks syntax only; no document is
+// queried.
+// Args: path (string)
+// Returns: map with segments and modifiers (fields for multipath queries,
+// with offsets relative to each field's path) OR error field with the byte
+// offset of the problem in position
+func explainQuery(this js.Value, args []js.Value) (result any) {
+	// Panic recovery with safe error return
+	defer func() {
+		if r := recover(); r != nil {
+			result = makeError(codeInternal, "Failed to explain query")
+		}
+	}()
+
+	// Validate argument count
+	if len(args) != 1 {
+		return makeError(codeInvalidArgument, "Expected 1 argument: path")
+	}
+	if args[0].Type() != js.TypeString {
+		return makeError(codeInvalidArgument, "First argument (path) must be a string")
+	}
+
+	path, errResult := checkPath(args[0].String())
+	if errResult != nil {
+		return errResult
+	}
+
+	if !isMultipath(path) {
+		explanation, err := explainPath(path)
+		if err != nil {
+			return err.response()
+		}
+		return explanation
+	}
+
+	fields, err := parseMultipath(path)
+	if err != nil {
+		return errorResponse(err)
+	}
+	explained := make([]any, 0, len(fields))
+	for _, field := range fields {
+		explanation, err := explainPath(field.path)
+		if err != nil {
+			explanation = err.response()
+		}
+		explanation["key"] = field.key
+		explained = append(explained, explanation)
+	}
+	return map[string]any{
+		"path":   path,
+		"fields": explained,
+	}
+}
+
+// explainError is a syntax error found by explainPath.
+type explainError struct {
+	message  string
+	position int // byte offset into the path
+}
+
+// response converts the error into a makeError response with its position.
+func (e *explainError) response() map[string]any {
+	response := makeError(codeInvalidPath, e.message)
+	response["position"] = e.position
+	return response
+}
+
+// explainPath splits a single path into described segments and modifiers,
+// following the syntax evaluate accepts.
+func explainPath(path string) (map[string]any, *explainError) {
+	if inner, ok := cutNormalizeSpace(path); ok {
+		return explainFunction(path, inner)
+	}
+
+	// Check escapes and parentheses, and find where the modifiers begin
+	depth, open := 0, -1
+	end := len(path)
+scan:
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '\\':
+			if i+1 == len(path) {
+				return nil, &explainError{"Escape at end of path", i}
+			}
+			i++
+		case '(':
+			if depth == 0 {
+				open = i
+			}
+			depth++
+		case ')':
+			if depth == 0 {
+				return nil, &explainError{"Unexpected ')'", i}
+			}
+			depth--
+		case '|':
+			if depth == 0 {
+				end = i
+				break scan
+			}
+		}
+	}
+	if depth > 0 {
+		return nil, &explainError{"Unclosed '('", open}
+	}
+
+	segments := []any{}
+	for _, bounds := range segmentBounds(path[:end]) {
+		if bounds[0] == bounds[1] {
+			return nil, &explainError{"Empty path segment", bounds[0]}
+		}
+		text := path[bounds[0]:bounds[1]]
+		if err := checkPredicates(text); err != nil {
+			return nil, &explainError{err.Error(), bounds[0] + strings.IndexByte(text, '[')}
+		}
+		segment := describeSegment(text)
+		segment["start"], segment["end"] = bounds[0], bounds[1]
+		segments = append(segments, segment)
+	}
+	for i, segment := range segments {
+		if described := segment.(map[string]any); described["kind"] == "count" && i < len(segments)-1 {
+			described["kind"] = "allMatches"
+		}
+	}
+
+	modifiers := []any{}
+	if end < len(path) {
+		offset := end
+		for _, modifier := range strings.Split(path[end+1:], "|") {
+			offset++ // the '|' separator
+			name := strings.TrimSpace(modifier)
+			if !strings.HasPrefix(name, "@") || len(name) == 1 {
+				return nil, &explainError{"Modifier must be written as @name", offset}
+			}
+			if xmldot.GetModifier(name[1:]) == nil {
+				return nil, &explainError{fmt.Sprintf("Unknown modifier %s", name), offset}
+			}
+			modifiers = append(modifiers, map[string]any{
+				"name":  name,
+				"start": offset,
+				"end":   offset + len(modifier),
+			})
+			offset += len(modifier)
+		}
+		if len(modifiers) > xmldot.MaxModifierChainDepth {
+			return nil, &explainError{fmt.Sprintf("Too many modifiers (max %d)", xmldot.MaxModifierChainDepth), end}
+		}
+	}
+
+	return map[string]any{
+		"path":      path,
+		"segments":  segments,
+		"modifiers": modifiers,
+	}, nil
+}
+
+// explainFunction explains the path wrapped by normalize-space(), with
+// offsets relative to the whole path.
+func explainFunction(path, inner string) (map[string]any, *explainError) {
+	shift := strings.Index(path, inner)
+	explanation, err := explainPath(inner)
+	if err != nil {
+		err.position += shift
+		return nil, err
+	}
+	for _, key := range []string{"segments", "modifiers"} {
+		for _, item := range explanation[key].([]any) {
+			described := item.(map[string]any)
+			described["start"] = described["start"].(int) + shift
+			described["end"] = described["end"].(int) + shift
+		}
+	}
+	explanation["path"] = path
+	explanation["function"] = "normalize-space"
+	return explanation, nil
+}
+
+// segmentBounds returns the [start, end) offsets of the dot-separated
+// segments of path, skipping escaped dots and dots inside parentheses.
+func segmentBounds(path string) [][2]int {
+	var bounds [][2]int
+	start, depth := 0, 0
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '.':
+			if depth == 0 {
+				bounds = append(bounds, [2]int{start, i})
+				start = i + 1
+			}
+		}
+	}
+	return append(bounds, [2]int{start, len(path)})
+}
+
+// describeSegment classifies a single path segment.
+func describeSegment(segment string) map[string]any {
+	if base, position, ok := cutPosition(segment); ok {
+		described := describeSegment(base)
+		described["text"] = segment
+		described["position"] = strings.TrimSpace(position)
+		return described
+	}
+
+	described := map[string]any{"text": segment}
+	switch {
+	case segment == "**":
+		described["kind"] = "descendant"
+	case segment == "@*":
+		described["kind"] = "attributeWildcard"
+	case segment == namespaceDeclTest:
+		described["kind"] = "namespaceDeclarations"
+	case strings.HasPrefix(segment, "@"+qualifiedNamePrefix):
+		described["kind"] = "attribute"
+		if uri, local, ok := cutQualifiedAttribute(segment); ok {
+			described["name"] = local
+			described["namespace"] = uri
+		} else {
+			described["name"] = unescapePath(segment[1:])
+		}
+	case strings.HasPrefix(segment, "@"):
+		described["kind"] = "attribute"
+		described["name"] = unescapePath(segment[1:])
+	case segment == "#":
+		described["kind"] = "count"
+	case strings.HasPrefix(segment, "#(") && strings.HasSuffix(segment, ")#"):
+		described["kind"] = "filter"
+		described["condition"] = segment[2 : len(segment)-2]
+		described["all"] = true
+	case strings.HasPrefix(segment, "#(") && strings.HasSuffix(segment, ")"):
+		described["kind"] = "filter"
+		described["condition"] = segment[2 : len(segment)-1]
+		described["all"] = false
+	case segment == "%":
+		described["kind"] = "text"
+	case segment == parentTest:
+		described["kind"] = "parent"
+	case segment == textTest:
+		described["kind"] = "textNodes"
+	case segment == followingSiblingTest:
+		described["kind"] = "followingSibling"
+	case segment == commentTest:
+		described["kind"] = "comment"
+	case segment == precedingCommentTest:
+		described["kind"] = "precedingComment"
+	case strings.HasPrefix(segment, piTestPrefix):
+		described["kind"] = "processingInstruction"
+		if target, ok := parsePITest(segment); ok && target != "" {
+			described["target"] = target
+		}
+	case isIndex(segment):
+		index, _ := strconv.Atoi(segment)
+		described["kind"] = "index"
+		described["index"] = index
+	case strings.HasPrefix(segment, anyNamespacePrefix) && !strings.ContainsAny(segment[2:], "*?"):
+		described["kind"] = "element"
+		described["name"] = unescapePath(segment[2:])
+		described["anyNamespace"] = true
+	case strings.ContainsAny(strings.ReplaceAll(segment, "\\*", ""), "*?"):
+		described["kind"] = "wildcard"
+	default:
+		described["kind"] = "element"
+		name := unescapePath(segment)
+		described["name"] = name
+		if prefix, _ := splitName(name); prefix != "" {
+			described["prefix"] = prefix
+		}
+	}
+	return described
+}
+
+// isIndex reports whether segment is a (possibly negative) integer index.
+func isIndex(segment string) bool {
+	digits := strings.TrimPrefix(segment, "-")
+	return digits != "" && strings.Trim(digits, "0123456789") == ""
+}
+
+// unescapePath removes backslash escapes from a path segment.
+func unescapePath(segment string) string {
+	var name strings.Builder
+	for i := 0; i < len(segment); i++ {
+		if segment[i] == '\\' && i+1 < len(segment) {
+			i++
+		}
+		name.WriteByte(segment[i])
+	}
+	return name.String()
+}
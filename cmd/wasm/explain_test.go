@@ -0,0 +1,28 @@
+//go:build js && wasm
+
+package main
+
+import "testing"
+
+func TestExplainAgreesWithQueryOnPositions(t *testing.T) {
+	const xml = `<a><b id="1">1</b><b>2</b></a>`
+	for _, path := range []string{
+		"a.b[x]", "a.b[0]", "a.b[last()]", "a.b[last()-x]", "a.b[-1]", "a.b[+]", "a.b[]",
+		"a.b[@id]", "a.b[not(@id)]", "a.b[@id=1]", "a.b[@id][x]",
+		"a.b[position()<3]", "a.b[position()=x]", "a[1].b[x]",
+	} {
+		t.Run(path, func(t *testing.T) {
+			queryFailed := call(t, executeQuery, xml, path)["code"] == codeInvalidPath
+			explained := call(t, explainQuery, path)
+			if explainFailed := explained["code"] == codeInvalidPath; explainFailed != queryFailed {
+				t.Errorf("explainQuery = %v, but executeQuery invalidPath = %v", explained, queryFailed)
+			}
+		})
+	}
+}
+
+func TestExplainPositionErrorOffset(t *testing.T) {
+	if response := call(t, explainQuery, "a[1].b[x]"); response["position"] != 6 {
+		t.Errorf("got %v, want the error at offset 6", response)
+	}
+}
@@ -0,0 +1,272 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"syscall/js"
+
+	"github.com/netascode/xmldot"
+)
+
+// MaxIndentSize caps the indentation unit accepted by prettifyXML and
+// xmlToJSON.
+const MaxIndentSize = 16
+
+// prettifyXML reformats a document with one element per line.
+// Args: xml (string), indent (optional string of spaces or tabs, default two
+// spaces)
+// Returns: map with result field OR error field
+func prettifyXML(this js.Value, args []js.Value) (result any) {
+	// Panic recovery with safe error return
+	defer func() {
+		if r := recover(); r != nil {
+			result = makeError(codeInternal, "Formatting failed due to resource limits or invalid input")
+		}
+	}()
+
+	// Validate argument count
+	if len(args) != 1 && len(args) != 2 {
+		return makeError(codeInvalidArgument, "Expected 1 or 2 arguments: xml and optional indent")
+	}
+
+	xml, errResult := formatArg(args[0])
+	if errResult != nil {
+		return errResult
+	}
+
+	indent := "  "
+	if len(args) == 2 && !args[1].IsUndefined() {
+		var err error
+		if indent, err = indentArg(args[1]); err != nil {
+			return errorResponse(err)
+		}
+	}
+
+	root, err := parseFormatTree(xml)
+	if err != nil {
+		return makeError(codeMalformed, "Invalid XML document")
+	}
+
+	var out strings.Builder
+	for _, item := range root.children {
+		writePretty(&out, item, indent, 0)
+	}
+	return mutationResult(strings.TrimSuffix(out.String(), "\n"))
+}
+
+// indentArg validates the optional indent argument of prettifyXML and
+// xmlToJSON.
+func indentArg(arg js.Value) (string, error) {
+	if arg.Type() != js.TypeString {
+		return "", fmt.Errorf("Second argument (indent) must be a string")
+	}
+	indent := arg.String()
+	if i := strings.IndexFunc(indent, func(r rune) bool { return r != ' ' && r != '\t' }); i >= 0 {
+		return "", fmt.Errorf("Indent must contain only spaces or tabs, found %q", []rune(indent[i:])[0])
+	}
+	if len(indent) > MaxIndentSize {
+		return "", fmt.Errorf("Indent must be at most %d spaces or tabs, got %d", MaxIndentSize, len(indent))
+	}
+	return indent, nil
+}
+
+// minifyXML removes whitespace between elements.
+// Args: xml (string)
+// Returns: map with result field OR error field
+func minifyXML(this js.Value, args []js.Value) (result any) {
+	// Panic recovery with safe error return
+	defer func() {
+		if r := recover(); r != nil {
+			result = makeError(codeInternal, "Formatting failed due to resource limits or invalid input")
+		}
+	}()
+
+	// Validate argument count
+	if len(args) != 1 {
+		return makeError(codeInvalidArgument, "Expected 1 argument: xml")
+	}
+
+	xml, errResult := formatArg(args[0])
+	if errResult != nil {
+		return errResult
+	}
+
+	root, err := parseFormatTree(xml)
+	if err != nil {
+		return makeError(codeMalformed, "Invalid XML document")
+	}
+
+	var out strings.Builder
+	writeMinified(&out, root)
+	return mutationResult(out.String())
+}
+
+// formatArg validates and converts the document argument of the formatting
+// bindings. Malformed documents are rejected before formatting, and a leading
+// byte order mark is dropped from the output.
+func formatArg(xmlArg js.Value) (string, map[string]any) {
+	if xmlArg.Type() != js.TypeString {
+		return "", makeError(codeInvalidArgument, "First argument (xml) must be a string")
+	}
+	xml := trimBOM(xmlArg.String())
+	if xmlLen := len(xml); xmlLen > xmlSizeLimit {
+		return "", makeError(codeTooLarge, fmt.Sprintf("XML too large (%d bytes, max %d)", xmlLen, xmlSizeLimit))
+	}
+	if err := encodingError(xml); err != nil {
+		return "", encodingResponse(err)
+	}
+	// The DOCTYPE is checked on its own and kept as written in the output
+	if masked, _, err := readDoctype(xml); err != nil || !xmldot.Valid(masked) {
+		return "", makeError(codeMalformed, "Invalid XML document")
+	}
+	return xml, nil
+}
+
+// formatItem is a node of a document as written in the source. Markup is
+// kept verbatim so formatting never alters tags, attribute order, quoting,
+// entity references, CDATA sections, comments or processing instructions.
+type formatItem struct {
+	raw      string // start tag for elements; the source text otherwise
+	endTag   string // source end tag; empty for self-closing elements
+	element  bool
+	text     bool // character data, including CDATA sections
+	preserve bool // xml:space="preserve" is in scope
+	children []*formatItem
+}
+
+// parseFormatTree splits doc into a tree of formatItems. The returned item
+// is a synthetic document node holding the top-level items.
+func parseFormatTree(doc string) (*formatItem, error) {
+	decoder := xml.NewDecoder(strings.NewReader(doc))
+	decoder.Strict = false
+	decoder.CharsetReader = passThroughCharset
+
+	root := &formatItem{element: true}
+	stack := []*formatItem{root}
+	for {
+		offset := int(decoder.InputOffset())
+		token, err := decoder.RawToken()
+		if err != nil {
+			if len(stack) != 1 {
+				return nil, err
+			}
+			return root, nil
+		}
+
+		current := stack[len(stack)-1]
+		raw := doc[offset:decoder.InputOffset()]
+		switch t := token.(type) {
+		case xml.StartElement:
+			if len(stack) > xmldot.MaxNestingDepth {
+				return nil, &xml.SyntaxError{Msg: "nesting depth exceeds limit"}
+			}
+			child := &formatItem{raw: raw, element: true, preserve: current.preserve}
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xml" && attr.Name.Local == "space" {
+					child.preserve = attr.Value == "preserve"
+				}
+			}
+			current.children = append(current.children, child)
+			stack = append(stack, child)
+		case xml.EndElement:
+			if len(stack) == 1 {
+				return nil, &xml.SyntaxError{Msg: "unexpected end element"}
+			}
+			current.endTag = raw // empty for the synthesized end of a self-closing tag
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			current.children = append(current.children, &formatItem{raw: raw, text: true})
+		default:
+			// Comments, processing instructions and directives
+			current.children = append(current.children, &formatItem{raw: raw})
+		}
+	}
+}
+
+// hasText reports whether an element holds character data other than
+// whitespace between its children, i.e. mixed or text content, where
+// reformatting would change the text.
+func (item *formatItem) hasText() bool {
+	for _, child := range item.children {
+		if child.text && strings.TrimSpace(child.raw) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// textOnly reports whether all of an element's children are character data,
+// as in "<a>   </a>", so that it is a leaf whatever the text.
+func (item *formatItem) textOnly() bool {
+	for _, child := range item.children {
+		if !child.text {
+			return false
+		}
+	}
+	return true
+}
+
+// hasElements reports whether an element has child elements, between which
+// whitespace-only text is indentation rather than content.
+func (item *formatItem) hasElements() bool {
+	for _, child := range item.children {
+		if child.element {
+			return true
+		}
+	}
+	return false
+}
+
+// writeVerbatim writes an item exactly as it appeared in the source.
+func writeVerbatim(out *strings.Builder, item *formatItem) {
+	out.WriteString(item.raw)
+	for _, child := range item.children {
+		writeVerbatim(out, child)
+	}
+	out.WriteString(item.endTag)
+}
+
+// writePretty writes item on its own line(s) at the given depth. Elements
+// with text content, only whitespace text or xml:space="preserve" are
+// written verbatim on one line; whitespace-only text between other nodes is
+// dropped.
+func writePretty(out *strings.Builder, item *formatItem, indent string, depth int) {
+	if item.text && strings.TrimSpace(item.raw) == "" {
+		return
+	}
+
+	out.WriteString(strings.Repeat(indent, depth))
+	if !item.element || item.textOnly() || item.preserve || item.hasText() {
+		writeVerbatim(out, item)
+		out.WriteByte('\n')
+		return
+	}
+
+	out.WriteString(item.raw)
+	out.WriteByte('\n')
+	for _, child := range item.children {
+		writePretty(out, child, indent, depth+1)
+	}
+	out.WriteString(strings.Repeat(indent, depth))
+	out.WriteString(item.endTag)
+	out.WriteByte('\n')
+}
+
+// writeMinified writes item without whitespace-only text between elements.
+// Whitespace in text or mixed content, in elements without child elements
+// ("<a>   </a>"), CDATA sections and xml:space="preserve" scopes is kept as
+// written; a nested xml:space="default" is minified again.
+func writeMinified(out *strings.Builder, item *formatItem) {
+	out.WriteString(item.raw)
+	keepWhitespace := item.preserve || item.hasText() || !item.hasElements()
+	for _, child := range item.children {
+		if child.text && !keepWhitespace && strings.TrimSpace(child.raw) == "" {
+			continue
+		}
+		writeMinified(out, child)
+	}
+	out.WriteString(item.endTag)
+}
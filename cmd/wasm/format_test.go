@@ -0,0 +1,92 @@
+//go:build js && wasm
+
+package main
+
+import "testing"
+
+func TestPrettifyKeepsWhitespaceOnlyElements(t *testing.T) {
+	tests := []struct {
+		name string
+		xml  string
+		want string
+	}{
+		{
+			name: "spaces",
+			xml:  "<r><a>   </a><b/></r>",
+			want: "<r>\n  <a>   </a>\n  <b/>\n</r>",
+		},
+		{
+			name: "newline",
+			xml:  "<r><a>\n</a></r>",
+			want: "<r>\n  <a>\n</a>\n</r>",
+		},
+		{
+			name: "indentation between elements",
+			xml:  "<r>\n    <a>x</a>\n</r>",
+			want: "<r>\n  <a>x</a>\n</r>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mustResult(t, prettifyXML, tt.xml, "  "); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMinifyKeepsWhitespaceOnlyElements(t *testing.T) {
+	tests := []struct {
+		name string
+		xml  string
+		want string
+	}{
+		{
+			name: "spaces",
+			xml:  "<r>\n  <a>   </a>\n  <b/>\n</r>",
+			want: "<r><a>   </a><b/></r>",
+		},
+		{
+			name: "comment",
+			xml:  "<r><a> <!--c--> </a></r>",
+			want: "<r><a> <!--c--> </a></r>",
+		},
+		{
+			name: "indentation between elements",
+			xml:  "<r>\n  <a>\n    <b>x</b>\n  </a>\n</r>",
+			want: "<r><a><b>x</b></a></r>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mustResult(t, minifyXML, tt.xml); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndentArgument(t *testing.T) {
+	tests := []struct {
+		indent string
+		want   string
+	}{
+		{indent: "\t-", want: `Indent must contain only spaces or tabs, found '-'`},
+		{indent: "  é", want: `Indent must contain only spaces or tabs, found 'é'`},
+		{indent: "                 ", want: "Indent must be at most 16 spaces or tabs, got 17"},
+	}
+	for _, binding := range []struct {
+		name string
+		fn   binding
+	}{{"prettifyXML", prettifyXML}, {"xmlToJSON", xmlToJSON}} {
+		for _, tt := range tests {
+			response := call(t, binding.fn, "<a/>", tt.indent)
+			if response["error"] != tt.want || response["code"] != codeInvalidArgument {
+				t.Errorf("%s(%q) = %v, want %q", binding.name, tt.indent, response, tt.want)
+			}
+		}
+		if got := mustResult(t, binding.fn, "<a><b/></a>", "\t \t"); got == "" {
+			t.Errorf("%s rejected a mixed tab and space indent", binding.name)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+//go:build js && wasm
+
+package main
+
+import "testing"
+
+// FuzzExecuteQuery runs arbitrary documents and paths through executeQuery.
+// Every binding recovers from panics and reports them with code "internal",
+// so that code is what marks a crash here. Go's fuzzing engine does not run
+// on js/wasm; "go test" runs the seed corpus below, which includes the
+// inputs of crashes fixed so far.
+func FuzzExecuteQuery(f *testing.F) {
+	seeds := []struct{ xml, path string }{
+		{smallConfig, "config.interfaces.interface.#(mtu>1500)#.name"},
+		{smallConfig, "**.name|@reverse"},
+		{smallConfig, "config.interfaces.interface[last()].parent()"},
+		{splitScript, "0"},
+		{splitScript, "config.script.#(@name==check)"},
+		{devices, "0.parent()"},
+		{devices, "0.following-sibling()"},
+		{devices, "r.d.interface[position() mod 2 = 1].name"},
+		{laughs, "r.a"},
+		{`<!DOCTYPE r [<!ENTITY a "&a;">]><r/>`, "r"},
+		{`<r><!-- c --><?pi x?><a>1</a></r>`, "r.comment()[1].following-sibling()"},
+		{`<r><a>1</a>`, "r.a"},
+		{`<r/>`, "#(a==1)#.b[x].*"},
+		{"", "a"},
+	}
+	for _, seed := range seeds {
+		f.Add(seed.xml, seed.path)
+	}
+	f.Fuzz(func(t *testing.T, xml, path string) {
+		if response := call(t, executeQuery, xml, path); response["code"] == codeInternal {
+			t.Fatalf("executeQuery(%q, %q) = %v", xml, path, response)
+		}
+	})
+}
@@ -0,0 +1,388 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"syscall/js"
+	"unicode"
+
+	"github.com/netascode/xmldot"
+)
+
+// xmlToJSON converts a document to JSON. With an indent, the JSON is written
+// one member or element per line, each level indented once more, as
+// JSON.stringify does; members keep their order and the structure is that of
+// the compact form. The conversion keeps elements, attributes and text but
+// loses the rest of the document:
+//   - comments, processing instructions and the XML declaration are dropped;
+//   - CDATA sections become plain text, escaped again by jsonToXML;
+//   - in mixed content the text is joined into one "#text" member ahead of
+//     the child elements, so <p>Hello <b>w</b>!</p> comes back from
+//     jsonToXML as <p>Hello !<b>w</b></p>;
+//   - siblings of one name are grouped into an array at the first of them,
+//     so <a/><b/><a/> comes back as <a/><a/><b/>;
+//   - empty elements, <x></x> or <x/>, become null.
+//
+// A document with more than one top-level element has no single JSON root
+// and is rejected as malformed.
+// Args: xml (string), indent (optional string of spaces or tabs; empty or
+// omitted for compact JSON)
+// Returns: map with result field (JSON text) OR error field
+func xmlToJSON(this js.Value, args []js.Value) (result any) {
+	// Panic recovery with safe error return
+	defer func() {
+		if r := recover(); r != nil {
+			result = makeError(codeInternal, "Conversion failed due to resource limits or invalid input")
+		}
+	}()
+
+	// Validate argument count
+	if len(args) != 1 && len(args) != 2 {
+		return makeError(codeInvalidArgument, "Expected 1 or 2 arguments: xml and optional indent")
+	}
+
+	xml, errResult := formatArg(args[0])
+	if errResult != nil {
+		return errResult
+	}
+
+	indent := ""
+	if len(args) == 2 && !args[1].IsUndefined() {
+		var err error
+		if indent, err = indentArg(args[1]); err != nil {
+			return errorResponse(err)
+		}
+	}
+
+	root, err := parseJSONTree(xml)
+	if err != nil {
+		return makeError(codeMalformed, "Invalid XML document")
+	}
+	if len(root.children) != 1 {
+		return makeError(codeMalformed, fmt.Sprintf("Document must have a single root element, found %d", len(root.children)))
+	}
+
+	var out strings.Builder
+	writeJSONObject(&out, root)
+	json := out.String()
+	if indent != "" {
+		json = indentJSON(json, indent)
+	}
+	return map[string]any{
+		"result": json,
+	}
+}
+
+// elementJSON converts the element n of doc and its descendants to JSON in
+// the xmlToJSON convention, as an object with the element's name as its
+// only member.
+func elementJSON(doc string, n *node) string {
+	root, err := parseJSONTree(doc[n.start:n.end])
+	if err != nil {
+		return ""
+	}
+
+	var out strings.Builder
+	writeJSONObject(&out, root)
+	return out.String()
+}
+
+// jsonElement is an element collected for JSON conversion.
+type jsonElement struct {
+	name     string
+	attrs    []xml.Attr
+	text     []string
+	children []*jsonElement
+}
+
+// parseJSONTree collects the elements of doc. The returned element is a
+// synthetic document node holding the root element.
+func parseJSONTree(doc string) (*jsonElement, error) {
+	decoder := xml.NewDecoder(strings.NewReader(doc))
+	decoder.Strict = false
+	decoder.CharsetReader = passThroughCharset
+
+	root := &jsonElement{}
+	stack := []*jsonElement{root}
+	for {
+		token, err := decoder.RawToken()
+		if err != nil {
+			if len(stack) != 1 {
+				return nil, err
+			}
+			return root, nil
+		}
+
+		current := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			if len(stack) > xmldot.MaxNestingDepth {
+				return nil, &xml.SyntaxError{Msg: "nesting depth exceeds limit"}
+			}
+			child := &jsonElement{name: qualifiedName(t.Name), attrs: t.Copy().Attr}
+			current.children = append(current.children, child)
+			stack = append(stack, child)
+		case xml.EndElement:
+			if len(stack) == 1 {
+				return nil, &xml.SyntaxError{Msg: "unexpected end element"}
+			}
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			current.text = append(current.text, string(t))
+		}
+	}
+}
+
+// textValue returns the text of e for the "#text" member. Text-only
+// elements keep their text exactly; in mixed content the non-blank text
+// segments are trimmed and joined with single spaces.
+func (e *jsonElement) textValue() string {
+	if len(e.children) == 0 {
+		return strings.Join(e.text, "")
+	}
+
+	var segments []string
+	for _, text := range e.text {
+		if text = strings.TrimSpace(text); text != "" {
+			segments = append(segments, text)
+		}
+	}
+	return strings.Join(segments, " ")
+}
+
+// writeJSONValue writes the JSON value of an element: null when empty, a
+// string for text-only elements, an object otherwise.
+func writeJSONValue(out *strings.Builder, e *jsonElement) {
+	if len(e.attrs) == 0 && len(e.children) == 0 {
+		if text := e.textValue(); text != "" {
+			writeJSONString(out, text)
+		} else {
+			out.WriteString("null")
+		}
+		return
+	}
+	writeJSONObject(out, e)
+}
+
+// writeJSONObject writes an element as an object. Members appear in document
+// order: attributes as "@name", then "#text", then child elements keyed by
+// name. Repeated children are grouped into an array at the position of the
+// first occurrence.
+func writeJSONObject(out *strings.Builder, e *jsonElement) {
+	out.WriteByte('{')
+	first := true
+	member := func(key string) {
+		if !first {
+			out.WriteByte(',')
+		}
+		first = false
+		writeJSONString(out, key)
+		out.WriteByte(':')
+	}
+
+	for _, attr := range e.attrs {
+		member("@" + qualifiedName(attr.Name))
+		writeJSONString(out, attr.Value)
+	}
+	if text := e.textValue(); text != "" && e.name != "" {
+		member("#text")
+		writeJSONString(out, text)
+	}
+
+	var names []string
+	groups := make(map[string][]*jsonElement)
+	for _, child := range e.children {
+		if _, seen := groups[child.name]; !seen {
+			names = append(names, child.name)
+		}
+		groups[child.name] = append(groups[child.name], child)
+	}
+	for _, name := range names {
+		member(name)
+		group := groups[name]
+		if len(group) == 1 {
+			writeJSONValue(out, group[0])
+			continue
+		}
+		out.WriteByte('[')
+		for i, child := range group {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			writeJSONValue(out, child)
+		}
+		out.WriteByte(']')
+	}
+	out.WriteByte('}')
+}
+
+// jsonToXML converts JSON in the xmlToJSON convention back to a document.
+// Documents that xmlToJSON converts without loss (see there) come back
+// unchanged, apart from formatting. JSON types do not survive the trip:
+//   - numbers and booleans are written as text, as written in the JSON
+//     ({"mtu":1500} becomes <mtu>1500</mtu>), and read back as strings;
+//   - an empty string and null both become an empty element, read back
+//     as null.
+//
+// Args: json (string)
+// Returns: map with result field (XML text) OR error field
+func jsonToXML(this js.Value, args []js.Value) (result any) {
+	// Panic recovery with safe error return
+	defer func() {
+		if r := recover(); r != nil {
+			result = makeError(codeInternal, "Conversion failed due to resource limits or invalid input")
+		}
+	}()
+
+	// Validate argument count
+	if len(args) != 1 {
+		return makeError(codeInvalidArgument, "Expected 1 argument: json")
+	}
+	if args[0].Type() != js.TypeString {
+		return makeError(codeInvalidArgument, "First argument (json) must be a string")
+	}
+	input := args[0].String()
+	if inputLen := len(input); inputLen > xmlSizeLimit {
+		return makeError(codeTooLarge, fmt.Sprintf("JSON too large (%d bytes, max %d)", inputLen, xmlSizeLimit))
+	}
+
+	document, err := parseJSONDocument(input)
+	var limitErr *codedError
+	if errors.As(err, &limitErr) {
+		return errorResponse(err)
+	}
+	if err != nil {
+		return makeError(codeMalformed, "Invalid JSON document")
+	}
+	if document.kind != jsonObject || len(document.members) != 1 ||
+		isSpecialKey(document.members[0].key) || document.members[0].value.kind == jsonArray {
+		return makeError(codeInvalidArgument, "JSON must be an object with a single root element")
+	}
+
+	var out strings.Builder
+	root := document.members[0]
+	if err := writeXMLElement(&out, root.key, root.value); err != nil {
+		return errorResponse(err)
+	}
+	return mutationResult(out.String())
+}
+
+// jsonKind is the type of a decoded JSON value.
+type jsonKind int
+
+const (
+	jsonNull jsonKind = iota
+	jsonScalar
+	jsonObject
+	jsonArray
+)
+
+// jsonValue is a decoded JSON value (see parseJSONDocument). Objects keep
+// their members in input order.
+type jsonValue struct {
+	kind    jsonKind
+	text    string // scalar value as text
+	members []jsonMember
+	items   []jsonValue
+}
+
+// jsonMember is a key/value pair of a JSON object.
+type jsonMember struct {
+	key   string
+	value jsonValue
+}
+
+// isSpecialKey reports whether key is an attribute or text member rather
+// than a child element.
+func isSpecialKey(key string) bool {
+	return strings.HasPrefix(key, "@") || key == "#text"
+}
+
+// writeXMLElement writes value as one element named name, or as repeated
+// elements when value is an array.
+func writeXMLElement(out *strings.Builder, name string, value jsonValue) error {
+	if !isXMLName(name) {
+		return fmt.Errorf("Invalid element name %q", name)
+	}
+
+	switch value.kind {
+	case jsonNull:
+		out.WriteString("<" + name + "/>")
+	case jsonScalar:
+		out.WriteString("<" + name + ">")
+		out.WriteString(textEscaper.Replace(value.text))
+		out.WriteString("</" + name + ">")
+	case jsonArray:
+		for _, item := range value.items {
+			if item.kind == jsonArray {
+				return fmt.Errorf("Nested arrays are not supported (element %q)", name)
+			}
+			if err := writeXMLElement(out, name, item); err != nil {
+				return err
+			}
+		}
+	case jsonObject:
+		var text string
+		var children []jsonMember
+		out.WriteString("<" + name)
+		for _, member := range value.members {
+			switch {
+			case member.key == "#text":
+				if member.value.kind != jsonScalar {
+					return fmt.Errorf("#text of element %q must be a string", name)
+				}
+				text = member.value.text
+			case strings.HasPrefix(member.key, "@"):
+				attrName := member.key[1:]
+				if !isXMLName(attrName) {
+					return fmt.Errorf("Invalid attribute name %q", attrName)
+				}
+				if member.value.kind != jsonScalar {
+					return fmt.Errorf("Attribute %q of element %q must be a string", attrName, name)
+				}
+				out.WriteString(" " + attrName + `="` + attrEscaper.Replace(member.value.text) + `"`)
+			default:
+				children = append(children, member)
+			}
+		}
+		if text == "" && len(children) == 0 {
+			out.WriteString("/>")
+			return nil
+		}
+		out.WriteString(">")
+		out.WriteString(textEscaper.Replace(text))
+		for _, child := range children {
+			if err := writeXMLElement(out, child.key, child.value); err != nil {
+				return err
+			}
+		}
+		out.WriteString("</" + name + ">")
+	}
+	return nil
+}
+
+var (
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "\n", "&#xA;", "\r", "&#xD;", "\t", "&#x9;")
+)
+
+// isXMLName reports whether name is usable as an element or attribute name:
+// a letter, '_' or ':' followed by letters, digits, '-', '.', '_' or ':'.
+func isXMLName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case unicode.IsLetter(r), r == '_', r == ':':
+		case i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+//go:build js && wasm
+
+package main
+
+import "testing"
+
+func TestXMLToJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		xml  string
+		want string
+	}{
+		{name: "repeated siblings", xml: `<r><i>1</i><i>2</i><n/></r>`, want: `{"r":{"i":["1","2"],"n":null}}`},
+		{name: "attributes and text", xml: `<a x="1">t</a>`, want: `{"a":{"@x":"1","#text":"t"}}`},
+		{name: "namespaces", xml: `<a xmlns:n="u"><n:b>1</n:b></a>`, want: `{"a":{"@xmlns:n":"u","n:b":"1"}}`},
+		// Lossy cases documented on xmlToJSON
+		{name: "comments and PIs", xml: `<a><!--c--><?pi x?>1</a>`, want: `{"a":"1"}`},
+		{name: "CDATA", xml: `<a><![CDATA[<x>]]></a>`, want: `{"a":"<x>"}`},
+		{name: "mixed content", xml: `<p>Hello <b>w</b>!</p>`, want: `{"p":{"#text":"Hello !","b":"w"}}`},
+		{name: "empty element", xml: `<a></a>`, want: `{"a":null}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mustResult(t, xmlToJSON, tt.xml); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestXMLToJSONRejectsSeveralRoots(t *testing.T) {
+	if response := call(t, xmlToJSON, `<a/><b/>`); response["code"] != codeMalformed {
+		t.Errorf("got %v, want a malformed error", response)
+	}
+}
+
+func TestJSONToXML(t *testing.T) {
+	tests := []struct {
+		json string
+		want string
+		back string // xmlToJSON of the result
+	}{
+		{json: `{"r":{"@id":"1","i":["a","b"]}}`, want: `<r id="1"><i>a</i><i>b</i></r>`, back: `{"r":{"@id":"1","i":["a","b"]}}`},
+		// Lossy cases documented on jsonToXML
+		{json: `{"mtu":1500}`, want: `<mtu>1500</mtu>`, back: `{"mtu":"1500"}`},
+		{json: `{"a":{"@x":2.50,"@y":true}}`, want: `<a x="2.50" y="true"/>`, back: `{"a":{"@x":"2.50","@y":"true"}}`},
+		{json: `{"a":""}`, want: `<a></a>`, back: `{"a":null}`},
+		{json: `{"a":null}`, want: `<a/>`, back: `{"a":null}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			got := mustResult(t, jsonToXML, tt.json)
+			if got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+			if back := mustResult(t, xmlToJSON, got); back != tt.back {
+				t.Errorf("xmlToJSON = %s, want %s", back, tt.back)
+			}
+		})
+	}
+}
@@ -0,0 +1,387 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/netascode/xmldot"
+)
+
+// JSON text is read and written by hand rather than with encoding/json,
+// which would add well over a megabyte to the WebAssembly binary for the
+// little the conversions need: string literals, indentation and a decoder
+// keeping object members in order.
+
+// writeJSONString writes s as a JSON string literal. Markup characters are
+// not escaped, since the output is not embedded in HTML; invalid UTF-8 is
+// replaced with U+FFFD as encoding/json does.
+func writeJSONString(out *strings.Builder, s string) {
+	const hex = "0123456789abcdef"
+	out.WriteByte('"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				out.WriteByte('\\')
+				out.WriteByte(c)
+			case c == '\n':
+				out.WriteString(`\n`)
+			case c == '\r':
+				out.WriteString(`\r`)
+			case c == '\t':
+				out.WriteString(`\t`)
+			case c < 0x20:
+				out.WriteString(`\u00`)
+				out.WriteByte(hex[c>>4])
+				out.WriteByte(hex[c&0xF])
+			default:
+				out.WriteByte(c)
+			}
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			out.WriteString(`\ufffd`)
+		case r == '\u2028' || r == '\u2029':
+			// Line separators end JavaScript string literals in older engines
+			out.WriteString(`\u202`)
+			out.WriteByte(hex[r&0xF])
+		default:
+			out.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	out.WriteByte('"')
+}
+
+// jsonString returns s as a JSON string literal (see writeJSONString).
+func jsonString(s string) string {
+	var out strings.Builder
+	writeJSONString(&out, s)
+	return out.String()
+}
+
+// indentJSON writes compact, valid JSON one member or element per line, each
+// level indented once more, as JSON.stringify does. Empty objects and arrays
+// stay on one line.
+func indentJSON(compact, indent string) string {
+	var out strings.Builder
+	depth := 0
+	newline := func() {
+		out.WriteByte('\n')
+		out.WriteString(strings.Repeat(indent, depth))
+	}
+
+	for i := 0; i < len(compact); i++ {
+		switch c := compact[i]; c {
+		case '"':
+			end := i + 1
+			for compact[end] != '"' {
+				if compact[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			out.WriteString(compact[i : end+1])
+			i = end
+		case '{', '[':
+			out.WriteByte(c)
+			if i+1 < len(compact) && (compact[i+1] == '}' || compact[i+1] == ']') {
+				out.WriteByte(compact[i+1])
+				i++
+				continue
+			}
+			depth++
+			newline()
+		case '}', ']':
+			depth--
+			newline()
+			out.WriteByte(c)
+		case ',':
+			out.WriteByte(c)
+			newline()
+		case ':':
+			out.WriteString(": ")
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String()
+}
+
+// jsonParser reads a JSON document by recursive descent into jsonValues.
+// Scalars keep their text as written, numbers included.
+type jsonParser struct {
+	input string
+	pos   int
+}
+
+// parseJSONDocument reads input, which must hold exactly one JSON value.
+// Nesting is capped at the library's MaxNestingDepth, reported as a
+// codedError; other errors describe malformed JSON.
+func parseJSONDocument(input string) (jsonValue, error) {
+	p := &jsonParser{input: input}
+	value, err := p.value(0)
+	if err != nil {
+		return jsonValue{}, err
+	}
+	if p.skipSpace(); p.pos < len(p.input) {
+		return jsonValue{}, p.errorf("unexpected data after the value")
+	}
+	return value, nil
+}
+
+// errorf returns a syntax error at the current position.
+func (p *jsonParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+// skipSpace advances past JSON whitespace.
+func (p *jsonParser) skipSpace() {
+	for p.pos < len(p.input) {
+		switch p.input[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// value reads the value starting at the next non-space character.
+func (p *jsonParser) value(depth int) (jsonValue, error) {
+	if depth > xmldot.MaxNestingDepth {
+		return jsonValue{}, newError(codeDepthExceeded, "JSON nesting depth exceeds the maximum of %d", xmldot.MaxNestingDepth)
+	}
+
+	p.skipSpace()
+	if p.pos == len(p.input) {
+		return jsonValue{}, p.errorf("unexpected end of input")
+	}
+	switch c := p.input[p.pos]; {
+	case c == '{':
+		return p.object(depth)
+	case c == '[':
+		return p.array(depth)
+	case c == '"':
+		text, err := p.string()
+		return jsonValue{kind: jsonScalar, text: text}, err
+	case c == '-' || (c >= '0' && c <= '9'):
+		text, err := p.number()
+		return jsonValue{kind: jsonScalar, text: text}, err
+	}
+	for _, literal := range []string{"null", "true", "false"} {
+		if strings.HasPrefix(p.input[p.pos:], literal) {
+			p.pos += len(literal)
+			if literal == "null" {
+				return jsonValue{kind: jsonNull}, nil
+			}
+			return jsonValue{kind: jsonScalar, text: literal}, nil
+		}
+	}
+	return jsonValue{}, p.errorf("invalid character %q", p.input[p.pos])
+}
+
+// object reads an object, keeping its members in input order.
+func (p *jsonParser) object(depth int) (jsonValue, error) {
+	value := jsonValue{kind: jsonObject}
+	p.pos++ // '{'
+	if p.skipSpace(); p.pos < len(p.input) && p.input[p.pos] == '}' {
+		p.pos++
+		return value, nil
+	}
+	for {
+		if p.skipSpace(); p.pos == len(p.input) || p.input[p.pos] != '"' {
+			return jsonValue{}, p.errorf("expected an object key")
+		}
+		key, err := p.string()
+		if err != nil {
+			return jsonValue{}, err
+		}
+		if p.skipSpace(); p.pos == len(p.input) || p.input[p.pos] != ':' {
+			return jsonValue{}, p.errorf("expected ':' after an object key")
+		}
+		p.pos++
+		member, err := p.value(depth + 1)
+		if err != nil {
+			return jsonValue{}, err
+		}
+		value.members = append(value.members, jsonMember{key: key, value: member})
+		if done, err := p.separator('}'); done || err != nil {
+			return value, err
+		}
+	}
+}
+
+// array reads an array.
+func (p *jsonParser) array(depth int) (jsonValue, error) {
+	value := jsonValue{kind: jsonArray}
+	p.pos++ // '['
+	if p.skipSpace(); p.pos < len(p.input) && p.input[p.pos] == ']' {
+		p.pos++
+		return value, nil
+	}
+	for {
+		item, err := p.value(depth + 1)
+		if err != nil {
+			return jsonValue{}, err
+		}
+		value.items = append(value.items, item)
+		if done, err := p.separator(']'); done || err != nil {
+			return value, err
+		}
+	}
+}
+
+// separator reads the ',' between members or elements, or the closing
+// delimiter, reporting true for the latter.
+func (p *jsonParser) separator(closing byte) (bool, error) {
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		switch p.input[p.pos] {
+		case ',':
+			p.pos++
+			return false, nil
+		case closing:
+			p.pos++
+			return true, nil
+		}
+	}
+	return false, p.errorf("expected ',' or %q", closing)
+}
+
+// string reads a string literal and returns its unescaped text. Invalid
+// UTF-8 and lone surrogates are replaced with U+FFFD.
+func (p *jsonParser) string() (string, error) {
+	p.pos++ // opening quote
+	var text strings.Builder
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return text.String(), nil
+		case c < 0x20:
+			return "", p.errorf("control character in string")
+		case c == '\\':
+			r, err := p.escape()
+			if err != nil {
+				return "", err
+			}
+			text.WriteRune(r)
+		case c < utf8.RuneSelf:
+			text.WriteByte(c)
+			p.pos++
+		default:
+			r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+			text.WriteRune(r)
+			p.pos += size
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// escape reads the escape sequence at the current position, combining a
+// surrogate pair written as two \u escapes.
+func (p *jsonParser) escape() (rune, error) {
+	if p.pos+1 == len(p.input) {
+		return 0, p.errorf("unterminated string")
+	}
+	p.pos += 2
+	switch c := p.input[p.pos-1]; c {
+	case '"', '\\', '/':
+		return rune(c), nil
+	case 'b':
+		return '\b', nil
+	case 'f':
+		return '\f', nil
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case 'u':
+		r, ok := p.hex4()
+		if !ok {
+			return 0, p.errorf("invalid \\u escape")
+		}
+		if utf16.IsSurrogate(r) {
+			if strings.HasPrefix(p.input[p.pos:], `\u`) {
+				p.pos += 2
+				low, ok := p.hex4()
+				if !ok {
+					return 0, p.errorf("invalid \\u escape")
+				}
+				if pair := utf16.DecodeRune(r, low); pair != utf8.RuneError {
+					return pair, nil
+				}
+				p.pos -= 6 // the second escape stands on its own
+			}
+			return utf8.RuneError, nil
+		}
+		return r, nil
+	}
+	return 0, p.errorf("invalid escape character %q", p.input[p.pos-1])
+}
+
+// hex4 reads the four hexadecimal digits of a \u escape.
+func (p *jsonParser) hex4() (rune, bool) {
+	if p.pos+4 > len(p.input) {
+		return 0, false
+	}
+	code, err := strconv.ParseUint(p.input[p.pos:p.pos+4], 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	p.pos += 4
+	return rune(code), true
+}
+
+// number reads a number and returns it as written: an optional minus, an
+// integer part without leading zeros, then optional fraction and exponent.
+func (p *jsonParser) number() (string, error) {
+	start := p.pos
+	digits := func() int {
+		n := 0
+		for p.pos < len(p.input) && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+			p.pos++
+			n++
+		}
+		return n
+	}
+
+	if p.input[p.pos] == '-' {
+		p.pos++
+	}
+	if p.pos < len(p.input) && p.input[p.pos] == '0' {
+		p.pos++
+	} else if digits() == 0 {
+		return "", p.errorf("invalid number")
+	}
+	if p.pos < len(p.input) && p.input[p.pos] == '.' {
+		p.pos++
+		if digits() == 0 {
+			return "", p.errorf("invalid number")
+		}
+	}
+	if p.pos < len(p.input) && (p.input[p.pos] == 'e' || p.input[p.pos] == 'E') {
+		p.pos++
+		if p.pos < len(p.input) && (p.input[p.pos] == '+' || p.input[p.pos] == '-') {
+			p.pos++
+		}
+		if digits() == 0 {
+			return "", p.errorf("invalid number")
+		}
+	}
+	return p.input[start:p.pos], nil
+}
@@ -0,0 +1,75 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"syscall/js"
+)
+
+// Active resource limits, adjustable with configureLimits. The bindings read
+// these rather than the MaxXMLSize and MaxQuerySize defaults.
+var (
+	xmlSizeLimit   = MaxXMLSize
+	querySizeLimit = MaxQuerySize
+)
+
+// configureLimits adjusts the document and query size limits for subsequent
+// calls. maxXMLSize cannot exceed MaxXMLSize, since xmldot rejects larger
+// documents itself; maxQuerySize cannot exceed MaxQuerySizeCeiling.
+// Omitted fields keep their current value. Nothing changes if any value is
+// rejected.
+// Args: limits (object: maxXMLSize, maxQuerySize)
+// Returns: map with the active maxXMLSize and maxQuerySize OR error field
+func configureLimits(this js.Value, args []js.Value) (result any) {
+	// Panic recovery with safe error return
+	defer func() {
+		if r := recover(); r != nil {
+			result = makeError(codeInternal, "Failed to configure limits")
+		}
+	}()
+
+	// Validate argument count
+	if len(args) != 1 {
+		return makeError(codeInvalidArgument, "Expected 1 argument: limits")
+	}
+	if args[0].Type() != js.TypeObject {
+		return makeError(codeInvalidArgument, "First argument (limits) must be an object")
+	}
+
+	xmlSize, err := limitValue(args[0].Get("maxXMLSize"), "maxXMLSize", xmlSizeLimit, MaxXMLSize)
+	if err != nil {
+		return errorResponse(err)
+	}
+	querySize, err := limitValue(args[0].Get("maxQuerySize"), "maxQuerySize", querySizeLimit, MaxQuerySizeCeiling)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	xmlSizeLimit, querySizeLimit = xmlSize, querySize
+	return map[string]any{
+		"maxXMLSize":   xmlSizeLimit,
+		"maxQuerySize": querySizeLimit,
+	}
+}
+
+// limitValue validates a limit field: a positive integer no greater than
+// ceiling. An undefined field yields current.
+func limitValue(value js.Value, name string, current, ceiling int) (int, error) {
+	if value.IsUndefined() {
+		return current, nil
+	}
+	if value.Type() != js.TypeNumber {
+		return 0, fmt.Errorf("%s must be a number", name)
+	}
+
+	n := value.Float()
+	if n != math.Trunc(n) || n < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer", name)
+	}
+	if n > float64(ceiling) {
+		return 0, fmt.Errorf("%s exceeds the maximum of %d", name, ceiling)
+	}
+	return int(n), nil
+}
@@ -0,0 +1,226 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"syscall/js"
+	"unicode/utf8"
+)
+
+// Editor coordinates. Lines count from 1 and columns from 0, as in the
+// line and column of validation errors. A line ends at "\n", "\r\n" or a
+// lone "\r", the line breaks XML recognizes, and "\r\n" is one break.
+// Offsets and columns are JavaScript string indexes (UTF-16 code units),
+// like the range fields of executeQuery, unless the bytes option selects
+// UTF-8 bytes, the unit of xmldot and of validation error columns.
+
+// offsetToLineCol converts an offset into the document into a line and
+// column. An offset between the "\r" and "\n" of a line break is reported
+// at the "\r", the end of its line.
+// Args: xml (string), offset (non-negative integer, at most the document
+// length), options (optional object: bytes)
+// Returns: map with line and column fields OR error field
+func offsetToLineCol(this js.Value, args []js.Value) (result any) {
+	// Panic recovery with safe error return
+	defer func() {
+		if r := recover(); r != nil {
+			result = makeError(codeInternal, "Failed to convert offset")
+		}
+	}()
+
+	// Validate argument count
+	if len(args) != 2 && len(args) != 3 {
+		return makeError(codeInvalidArgument, "Expected 2 or 3 arguments: xml, offset and optional options")
+	}
+	doc, errResult := documentArg(args[0])
+	if errResult != nil {
+		return errResult
+	}
+	offset, err := integerArg(args[1], "Second argument (offset)")
+	if err != nil {
+		return errorResponse(err)
+	}
+	bytes, err := bytesOption(args, 2)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	index, ok := byteOffset(doc, offset, bytes)
+	if !ok {
+		return makeError(codeInvalidArgument, fmt.Sprintf("Offset %d is not a position in the document", offset))
+	}
+	if index > 0 && index < len(doc) && doc[index-1] == '\r' && doc[index] == '\n' {
+		index--
+	}
+
+	starts := lineStarts(doc)
+	line := sort.Search(len(starts), func(i int) bool { return starts[i] > index })
+	return map[string]any{
+		"line":   line,
+		"column": unitsBetween(doc, starts[line-1], index, bytes),
+	}
+}
+
+// lineColToOffset converts a line and column into an offset into the
+// document, the inverse of offsetToLineCol. The column may be the length
+// of the line, the position of its line break.
+// Args: xml (string), line (integer from 1), column (integer from 0),
+// options (optional object: bytes)
+// Returns: map with offset field OR error field
+func lineColToOffset(this js.Value, args []js.Value) (result any) {
+	// Panic recovery with safe error return
+	defer func() {
+		if r := recover(); r != nil {
+			result = makeError(codeInternal, "Failed to convert line and column")
+		}
+	}()
+
+	// Validate argument count
+	if len(args) != 3 && len(args) != 4 {
+		return makeError(codeInvalidArgument, "Expected 3 or 4 arguments: xml, line, column and optional options")
+	}
+	doc, errResult := documentArg(args[0])
+	if errResult != nil {
+		return errResult
+	}
+	line, err := integerArg(args[1], "Second argument (line)")
+	if err != nil {
+		return errorResponse(err)
+	}
+	column, err := integerArg(args[2], "Third argument (column)")
+	if err != nil {
+		return errorResponse(err)
+	}
+	bytes, err := bytesOption(args, 3)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	starts := lineStarts(doc)
+	if line < 1 || line > len(starts) {
+		return makeError(codeInvalidArgument, fmt.Sprintf("Line %d is out of range (the document has %d lines)", line, len(starts)))
+	}
+	start, end := starts[line-1], len(doc)
+	if line < len(starts) {
+		end = starts[line]
+		if doc[end-1] == '\n' {
+			end--
+		}
+		if end > start && doc[end-1] == '\r' {
+			end--
+		}
+	}
+
+	length := unitsBetween(doc, start, end, bytes)
+	if column > length {
+		return makeError(codeInvalidArgument, fmt.Sprintf("Column %d is past the end of line %d (length %d)", column, line, length))
+	}
+	index, ok := byteOffset(doc[start:end], column, bytes)
+	if !ok {
+		return makeError(codeInvalidArgument, fmt.Sprintf("Column %d splits a character on line %d", column, line))
+	}
+
+	offset := start + index
+	if !bytes {
+		offset = jsIndex(doc, offset)
+	}
+	return map[string]any{
+		"offset": offset,
+	}
+}
+
+// lineStarts returns the byte offsets at which the lines of doc start.
+func lineStarts(doc string) []int {
+	starts := []int{0}
+	for i := 0; i < len(doc); i++ {
+		switch doc[i] {
+		case '\r':
+			if i+1 < len(doc) && doc[i+1] == '\n' {
+				i++
+			}
+			starts = append(starts, i+1)
+		case '\n':
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+// byteOffset converts an offset in bytes or UTF-16 code units into a byte
+// offset into s. It reports false past the end of s and, for code units,
+// inside a character.
+func byteOffset(s string, offset int, bytes bool) (int, bool) {
+	if bytes {
+		return offset, offset <= len(s) && (offset == len(s) || utf8.RuneStart(s[offset]))
+	}
+	units := 0
+	for i, r := range s {
+		if units == offset {
+			return i, true
+		}
+		if units > offset {
+			return 0, false
+		}
+		units++
+		if r >= 0x10000 {
+			units++
+		}
+	}
+	return len(s), units == offset
+}
+
+// unitsBetween counts the bytes or UTF-16 code units of s[start:end].
+func unitsBetween(s string, start, end int, bytes bool) int {
+	if bytes {
+		return end - start
+	}
+	return jsIndex(s[start:end], end-start)
+}
+
+// documentArg validates the document argument of the coordinate bindings.
+func documentArg(xmlArg js.Value) (string, map[string]any) {
+	if xmlArg.Type() != js.TypeString {
+		return "", makeError(codeInvalidArgument, "First argument (xml) must be a string")
+	}
+	doc := xmlArg.String()
+	if xmlLen := len(doc); xmlLen > xmlSizeLimit {
+		return "", makeError(codeTooLarge, fmt.Sprintf("XML too large (%d bytes, max %d)", xmlLen, xmlSizeLimit))
+	}
+	return doc, nil
+}
+
+// integerArg reads a non-negative integer argument.
+func integerArg(value js.Value, name string) (int, error) {
+	if value.Type() != js.TypeNumber {
+		return 0, fmt.Errorf("%s must be a number", name)
+	}
+	n := value.Float()
+	if n < 0 || n != math.Trunc(n) || n > math.MaxInt32 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", name)
+	}
+	return int(n), nil
+}
+
+// bytesOption reads the optional options argument at index i of the
+// coordinate bindings, an object whose bytes field (boolean, default false)
+// selects UTF-8 bytes over JavaScript string indexes. Undefined or null
+// selects the default.
+func bytesOption(args []js.Value, i int) (bool, error) {
+	if len(args) <= i || args[i].IsUndefined() || args[i].IsNull() {
+		return false, nil
+	}
+	if args[i].Type() != js.TypeObject {
+		return false, fmt.Errorf("Options must be an object")
+	}
+	bytes := args[i].Get("bytes")
+	if bytes.IsUndefined() {
+		return false, nil
+	}
+	if bytes.Type() != js.TypeBoolean {
+		return false, fmt.Errorf("Option bytes must be a boolean")
+	}
+	return bytes.Bool(), nil
+}
@@ -4,19 +4,27 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"strings"
 	"syscall/js"
+	"time"
 
 	"github.com/netascode/xmldot"
 )
 
 // Resource limits (security controls)
 const (
-	MaxXMLSize   = 10 * 1024 * 1024 // 10MB - matches xmldot library limit
-	MaxQuerySize = 4096             // 4KB - prevents query complexity DoS
+	MaxXMLSize           = 10 * 1024 * 1024 // 10MB - matches xmldot library limit; default and ceiling
+	MaxQuerySize         = 4096             // 4KB - prevents query complexity DoS; default
+	MaxQuerySizeCeiling  = 64 * 1024        // 64KB - highest query size configureLimits accepts
+	MaxNamespaceBindings = 64               // caps prefix bindings per namespaced query
+	MaxMultipathFields   = 32               // caps fields per {a,b,c} multipath query
+	MaxBatchQueries      = 100              // caps paths per executeQueries call
+	MaxCachedQueries     = 64               // caps executeQuery responses cached for the current document
+	DefaultQueryTimeout  = 2000             // ms - default time budget per query call
+	MaxQueryTimeout      = 10000            // ms - ceiling for the timeoutMs option
 	// MaxWildcardResults = 1000 (enforced internally by xmldot library)
 	// MaxRecursiveOperations = 10000 (enforced internally by xmldot library)
-	// Note: Timeout temporarily disabled to debug WASM issues
 )
 
 func main() {
@@ -34,6 +42,12 @@ func main() {
 		panic("JavaScript console object not available")
 	}
 
+	// Register playground modifiers with the xmldot library
+	if err := registerModifiers(); err != nil {
+		console.Call("error", fmt.Sprintf("Failed to register modifiers: %v", err))
+		return
+	}
+
 	// Bind WASM functions to JavaScript
 	if err := bindWASMFunctions(); err != nil {
 		console.Call("error", fmt.Sprintf("Failed to bind WASM functions: %v", err))
@@ -59,74 +73,241 @@ func bindWASMFunctions() error {
 
 	// Bind functions
 	global.Set("executeQuery", js.FuncOf(executeQuery))
+	global.Set("executeQueries", js.FuncOf(executeQueries))
+	global.Set("pathExists", js.FuncOf(pathExists))
+	global.Set("executeQueryWithNamespaces", js.FuncOf(executeQueryWithNamespaces))
+	global.Set("listNamespaces", js.FuncOf(listNamespaces))
+	global.Set("describeNode", js.FuncOf(describeNode))
+	global.Set("setValue", js.FuncOf(setValue))
+	global.Set("deleteNode", js.FuncOf(deleteNode))
+	global.Set("prettifyXML", js.FuncOf(prettifyXML))
+	global.Set("minifyXML", js.FuncOf(minifyXML))
+	global.Set("xmlToJSON", js.FuncOf(xmlToJSON))
+	global.Set("jsonToXML", js.FuncOf(jsonToXML))
 	global.Set("validateXML", js.FuncOf(validateXML))
+	global.Set("validateXMLDetailed", js.FuncOf(validateXMLDetailed))
+	global.Set("offsetToLineCol", js.FuncOf(offsetToLineCol))
+	global.Set("lineColToOffset", js.FuncOf(lineColToOffset))
+	global.Set("diffXML", js.FuncOf(diffXML))
+	global.Set("roundTrip", js.FuncOf(roundTrip))
+	global.Set("canonicalizeXML", js.FuncOf(canonicalizeXML))
+	global.Set("xmlDeclaration", js.FuncOf(xmlDeclaration))
+	global.Set("explainQuery", js.FuncOf(explainQuery))
+	global.Set("xpathToQuery", js.FuncOf(xpathToQuery))
+	global.Set("queryToXPath", js.FuncOf(queryToXPath))
+	global.Set("escapePath", js.FuncOf(escapePath))
+	global.Set("suggestPaths", js.FuncOf(suggestPaths))
 	global.Set("getVersion", js.FuncOf(getVersion))
+	global.Set("configureLimits", js.FuncOf(configureLimits))
 
 	return nil
 }
 
 // executeQuery executes an XMLDOT query with resource limits and error handling.
-// Args: xml (string), path (string)
-// Returns: map with value, raw, exists, type, index fields OR error field
+// Responses are cached for repeated queries on the same document (see
+// queryCache).
+// Args: xml (string), path (string), options (optional object, see
+// parseQueryConfig)
+// Returns: map with value, raw, exists, empty, type and index fields, plus
+// the fields described at runQuery and metrics when requested, OR error
+// field
 func executeQuery(this js.Value, args []js.Value) (result any) {
 	// Panic recovery with safe error return
 	defer func() {
 		if r := recover(); r != nil {
-			result = makeError("Query execution failed due to resource limits or invalid input")
+			result = makeError(codeInternal, "Query execution failed due to resource limits or invalid input")
 		}
 	}()
 
 	// Validate argument count
-	if len(args) != 2 {
-		return makeError("Expected 2 arguments: xml and path")
+	if len(args) != 2 && len(args) != 3 {
+		return makeError(codeInvalidArgument, "Expected 2 or 3 arguments: xml, path and optional options")
 	}
 
+	xml, path, errResult := queryArgs(args[0], args[1])
+	if errResult != nil {
+		return errResult
+	}
+
+	config := defaultQueryConfig()
+	if len(args) == 3 {
+		var err error
+		if config, err = parseQueryConfig(args[2]); err != nil {
+			return errorResponse(err)
+		}
+	}
+
+	start := time.Now()
+	key := queries.cacheKey(xml, path, config)
+	response, cached := queries.get(key)
+	if !cached {
+		response = runWithTimeout(config.timeout, func() map[string]any {
+			return runQuery(xml, path, config)
+		})
+		queries.put(key, response)
+	}
+	if _, failed := response["error"]; config.metrics && !failed {
+		response["metrics"] = queryMetrics(xml, time.Since(start))
+	}
+	return response
+}
+
+// queryArgs validates and converts the xml and path arguments shared by the
+// query bindings. On failure it returns a makeError response instead.
+func queryArgs(xmlArg, pathArg js.Value) (xml, path string, errResult map[string]any) {
 	// Validate argument types before accessing
-	if args[0].Type() != js.TypeString {
-		return makeError("First argument (xml) must be a string")
+	if xmlArg.Type() != js.TypeString {
+		return "", "", makeError(codeInvalidArgument, "First argument (xml) must be a string")
 	}
-	if args[1].Type() != js.TypeString {
-		return makeError("Second argument (path) must be a string")
+	if pathArg.Type() != js.TypeString {
+		return "", "", makeError(codeInvalidArgument, "Second argument (path) must be a string")
 	}
 
 	// Convert to Go strings first (JavaScript strings are primitives, not objects)
 	// IMPORTANT: Cannot use .Get("length") on JavaScript strings - must convert first
-	xml := args[0].String()
-	path := args[1].String()
+	xml = xmlArg.String()
 
 	// Check sizes to prevent memory allocation bombs
-	xmlLen := len(xml)
-	pathLen := len(path)
+	if xmlLen := len(xml); xmlLen > xmlSizeLimit {
+		return "", "", makeError(codeTooLarge, fmt.Sprintf("XML too large (%d bytes, max %d)", xmlLen, xmlSizeLimit))
+	}
+	if err := encodingError(trimBOM(xml)); err != nil {
+		return "", "", encodingResponse(err)
+	}
 
-	if xmlLen > MaxXMLSize {
-		return makeError(fmt.Sprintf("XML too large (%d bytes, max %d)", xmlLen, MaxXMLSize))
+	path, errResult = checkPath(pathArg.String())
+	if errResult != nil {
+		return "", "", errResult
 	}
+	return xml, path, nil
+}
 
-	if pathLen > MaxQuerySize {
-		return makeError(fmt.Sprintf("Query too large (%d bytes, max %d)", pathLen, MaxQuerySize))
+// checkPath applies the size limit and basic validation to a query path and
+// returns it trimmed. On failure it returns a makeError response instead.
+func checkPath(path string) (string, map[string]any) {
+	if pathLen := len(path); pathLen > querySizeLimit {
+		return "", makeError(codeTooLarge, fmt.Sprintf("Query too large (%d bytes, max %d)", pathLen, querySizeLimit))
 	}
 
 	// Basic validation
 	path = strings.TrimSpace(path)
 	if path == "" {
-		return makeError("Query path cannot be empty")
+		return "", makeError(codeInvalidPath, "Query path cannot be empty")
 	}
+	return path, nil
+}
 
-	// Execute XMLDOT query
-	queryResult := xmldot.Get(xml, path)
+// runQuery executes a validated query and builds the structured response.
+// The query runs on the document with its internal DTD subset blanked out
+// (see readDoctype), and references are expanded in the value unless the
+// expandReferences option is off (see expandReferences).
+// Besides the fields of resultToMap, the response has results for Array
+// types; path, text (all descendant character data), namespaces (in-scope
+// prefix -> URI bindings), children ({name, path} of child elements), parent
+// (path; omitted for the document element) and json (with asJSON; also set
+// on located array items) for located elements; attributes and
+// attributeList (in source order) for plain element paths; cdata for CDATA
+// content; truncated and matchLimit when the match limit was hit; and range
+// (JavaScript string indexes of the matched element or attribute) when it
+// could be located.
+func runQuery(source, path string, config queryConfig) map[string]any {
+	xml, entities, doctypeErr := readDoctype(source)
+	if doctypeErr != nil {
+		return makeError(codeMalformed, "Invalid DOCTYPE declaration: "+doctypeErr.Message)
+	}
+	if config.strict {
+		if err := strictError(trimBOM(source)); err != nil {
+			return strictResponse(err)
+		}
+	}
+	if isMultipath(path) {
+		return runMultipath(xml, path, entities, config)
+	}
+
+	eval, err := evaluate(xml, path, config.opts)
+	if err != nil {
+		return errorResponse(err)
+	}
+	if config.expandReferences {
+		if eval.result, err = expandReferences(xml, eval, entities); err != nil {
+			return errorResponse(err)
+		}
+	}
+	if config.normalizeSpace {
+		eval.result = normalizeResult(eval.result)
+	}
 
 	// Return structured result
-	return map[string]any{
-		"value":  queryResult.String(),
-		"raw":    queryResult.Raw,
-		"exists": queryResult.Exists(),
-		"type":   typeToString(queryResult.Type),
-		"index":  queryResult.Index,
+	response := resultToMap(eval.result)
+	if eval.result.IsArray() {
+		items := arrayResults(eval.result)
+		for i, element := range eval.matches {
+			if element != nil {
+				items[i].(map[string]any)["path"] = element.canonicalPath()
+				if config.asJSON {
+					items[i].(map[string]any)["json"] = elementJSON(xml, element)
+				}
+			}
+		}
+		response["results"] = items
+	}
+	element := eval.element
+	if element == nil && !eval.result.IsArray() && len(eval.matches) == 1 {
+		element = eval.matches[0]
+	}
+	if element != nil {
+		response["path"] = element.canonicalPath()
+		text, err := expandedText(xml[element.innerStart:element.innerEnd], entities)
+		if err != nil {
+			return errorResponse(err)
+		}
+		response["text"] = text
+		response["namespaces"] = element.inScopeNamespaces()
+		response["children"] = element.childList()
+		if element.parent != nil && element.parent.parent != nil {
+			response["parent"] = element.parent.canonicalPath()
+		}
+		if config.asJSON {
+			response["json"] = elementJSON(xml, element)
+		}
+	}
+	if eval.element != nil {
+		response["attributes"] = eval.element.attributeMap()
+		response["attributeList"] = eval.element.attributeList()
+	}
+	if eval.cdata {
+		response["cdata"] = true
+	}
+	if isTruncated(eval.result, path) {
+		response["truncated"] = true
+		response["matchLimit"] = xmldot.MaxWildcardResults
 	}
+	if eval.span != nil {
+		response["range"] = map[string]any{
+			"start": jsIndex(source, eval.span.start),
+			"end":   jsIndex(source, eval.span.end),
+		}
+	}
+	return response
+}
+
+// jsIndex converts a byte offset in s into a JavaScript string index, which
+// counts UTF-16 code units.
+func jsIndex(s string, offset int) int {
+	index := 0
+	for _, r := range s[:offset] {
+		if r >= 0x10000 {
+			index += 2
+		} else {
+			index++
+		}
+	}
+	return index
 }
 
 // validateXML checks if XML is well-formed using XMLDOT's validation.
-// Args: xml (string)
+// Args: xml (string), options (optional object: strict, see
+// validationStrict)
 // Returns: bool
 func validateXML(this js.Value, args []js.Value) (result any) {
 	// Set default return value
@@ -141,7 +322,11 @@ func validateXML(this js.Value, args []js.Value) (result any) {
 	}()
 
 	// Validate argument count
-	if len(args) != 1 {
+	if len(args) != 1 && len(args) != 2 {
+		return false
+	}
+	strict, err := validationStrict(args)
+	if err != nil {
 		return false
 	}
 
@@ -154,28 +339,200 @@ func validateXML(this js.Value, args []js.Value) (result any) {
 	xml := args[0].String()
 
 	// Check size to prevent memory allocation bombs
-	if len(xml) > MaxXMLSize {
+	if len(xml) > xmlSizeLimit {
 		return false
 	}
 
-	return xmldot.Valid(xml)
+	xml = trimBOM(xml)
+	masked, _, doctypeErr := readDoctype(xml)
+	if doctypeErr != nil || !xmldot.Valid(masked) || duplicateAttribute(masked) != nil || encodingError(masked) != nil {
+		return false
+	}
+	return !strict || strictError(xml) == nil
 }
 
-// getVersion returns the XMLDOT version.
-// Args: none
-// Returns: string
-func getVersion(this js.Value, args []js.Value) any {
-	return "0.2.0"
+// validateXMLDetailed checks if XML is well-formed and reports where the
+// first problem is. Messages come from xmldot's validator, which is extended
+// here to reject repeated attribute names and unsupported encodings (see
+// supportedEncodings), to locate unterminated attribute values and to read
+// internal DTD subsets (see readDoctype), and only describe the document.
+// In strict mode the checks of strictError follow.
+// Args: xml (string), options (optional object: strict, see
+// validationStrict)
+// Returns: map with valid field, plus line, column and message when invalid
+// OR error field
+func validateXMLDetailed(this js.Value, args []js.Value) (result any) {
+	// Panic recovery with safe error return
+	defer func() {
+		if r := recover(); r != nil {
+			result = makeError(codeInternal, "Validation failed due to resource limits or invalid input")
+		}
+	}()
+
+	// Validate argument count
+	if len(args) != 1 && len(args) != 2 {
+		return makeError(codeInvalidArgument, "Expected 1 or 2 arguments: xml and optional options")
+	}
+
+	// Validate argument type
+	if args[0].Type() != js.TypeString {
+		return makeError(codeInvalidArgument, "First argument (xml) must be a string")
+	}
+	strict, optErr := validationStrict(args)
+	if optErr != nil {
+		return makeError(codeInvalidArgument, optErr.Error())
+	}
+
+	// Convert to Go string (JavaScript strings are primitives, not objects)
+	xml := args[0].String()
+
+	// Check size to prevent memory allocation bombs
+	if xmlLen := len(xml); xmlLen > xmlSizeLimit {
+		return makeError(codeTooLarge, fmt.Sprintf("XML too large (%d bytes, max %d)", xmlLen, xmlSizeLimit))
+	}
+
+	xml = trimBOM(xml)
+	masked, _, err := readDoctype(xml)
+	if err == nil {
+		err = xmldot.ValidateWithError(masked)
+	}
+	if err == nil {
+		err = duplicateAttribute(masked)
+	} else if strings.HasPrefix(err.Message, "unexpected end of document (expected '") {
+		if attrErr := unterminatedAttribute(masked); attrErr != nil {
+			err = attrErr
+		}
+	}
+	if err == nil {
+		err = encodingError(masked)
+	}
+	if err == nil && strict {
+		err = strictError(xml)
+	}
+	if err != nil {
+		return map[string]any{
+			"valid":   false,
+			"line":    err.Line,
+			"column":  err.Column,
+			"message": err.Message,
+		}
+	}
+	return map[string]any{
+		"valid": true,
+	}
+}
+
+// utf8BOM is the byte order mark some editors write at the start of UTF-8 files.
+const utf8BOM = "\uFEFF"
+
+// trimBOM strips a leading byte order mark. Queries read past it, but xmldot's
+// validator reports it as content outside the root element. Documents reach
+// Go as decoded JavaScript strings, so a supported encoding declaration such
+// as encoding="UTF-16" describes the original file and needs no decoding
+// here (see supportedEncodings).
+func trimBOM(xml string) string {
+	return strings.TrimPrefix(xml, utf8BOM)
+}
+
+// supportedEncodings are the encodings, in lower case, a document may
+// declare: UTF-8, UTF-16 and the ASCII-compatible Western encodings.
+// Documents arrive as decoded JavaScript strings and are read as UTF-8, so
+// for these the declaration only describes the original file. Other
+// encodings, such as Shift_JIS, are refused as malformed rather than read
+// as UTF-8.
+var supportedEncodings = map[string]bool{
+	"utf-8":        true,
+	"utf-16":       true,
+	"utf-16le":     true,
+	"utf-16be":     true,
+	"us-ascii":     true,
+	"ascii":        true,
+	"iso-8859-1":   true,
+	"latin1":       true,
+	"windows-1252": true,
+}
+
+// encodingError reports an XML declaration at the start of doc naming an
+// encoding that is not supported. The error points at the encoding name.
+func encodingError(doc string) *xmldot.ValidateError {
+	pseudoAttrs, ok := declarationAttrs(doc)
+	encoding := pseudoAttrs["encoding"]
+	if !ok || encoding == "" || supportedEncodings[strings.ToLower(encoding)] {
+		return nil
+	}
+	name := strings.Index(doc, "encoding")
+	offset := name + strings.Index(doc[name:], encoding)
+	return validateErrorAt(doc, offset, fmt.Sprintf("unsupported encoding '%s'", encoding))
+}
+
+// encodingResponse converts an unsupported encoding into an error response.
+func encodingResponse(err *xmldot.ValidateError) map[string]any {
+	return makeError(codeMalformed, "Invalid XML document: "+err.Message)
+}
+
+// passThroughCharset is the CharsetReader of the encoding/xml decoders
+// reading documents. Without one, a declared encoding other than UTF-8
+// fails decoding, though JavaScript strings arrive as UTF-8 whatever the
+// declaration says. Bindings refuse unsupported encodings before decoding
+// (see encodingError).
+func passThroughCharset(_ string, input io.Reader) (io.Reader, error) {
+	return input, nil
 }
 
-// makeError creates a standardized error response.
-// Only includes user-safe error messages - no stack traces or internal details.
-func makeError(message string) map[string]any {
+// resultToMap converts an xmldot.Result into the map shape returned to JavaScript.
+// For elements, raw is the markup between the start and end tags as written,
+// mixed content, comments and CDATA included, and empty for <x/>; it never
+// contains the element's own tags. exists is false only when nothing
+// matched; empty marks an element that is present but has no content (<x/>,
+// <x></x> or <x a="1"/>), so an explicitly empty element can be told apart
+// from a missing one.
+func resultToMap(r xmldot.Result) map[string]any {
 	return map[string]any{
-		"error": message,
+		"value":  resultValue(r),
+		"raw":    r.Raw,
+		"exists": r.Exists(),
+		"empty":  isEmptyElement(r),
+		"type":   typeToString(r.Type),
+		"index":  r.Index,
+	}
+}
+
+// isEmptyElement reports whether r is an element without content. Whitespace
+// counts as content, so <x> </x> is not empty.
+func isEmptyElement(r xmldot.Result) bool {
+	return r.Type == xmldot.Element && r.Raw == ""
+}
+
+// resultValue returns the value of r as a JavaScript-native type chosen by
+// its Type:
+//   - Null: an empty string (exists tells it apart from an empty match)
+//   - String, Element, Attribute: the text content as a string
+//   - Number: a number
+//   - True, False: a boolean
+//   - Array: the JSON-style string of its items, which are also returned
+//     individually in results
+func resultValue(r xmldot.Result) any {
+	switch r.Type {
+	case xmldot.Number:
+		return r.Float()
+	case xmldot.True, xmldot.False:
+		return r.Bool()
+	default:
+		return r.String()
 	}
 }
 
+// arrayResults converts each match of an Array result into its own result map.
+// Returned as []any because js.ValueOf does not accept typed slices.
+func arrayResults(r xmldot.Result) []any {
+	items := make([]any, 0, len(r.Results))
+	r.ForEach(func(index int, value xmldot.Result) bool {
+		items = append(items, resultToMap(value))
+		return true
+	})
+	return items
+}
+
 // typeToString converts xmldot.Type to string representation.
 func typeToString(t xmldot.Type) string {
 	switch t {
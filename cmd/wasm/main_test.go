@@ -0,0 +1,168 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"os"
+	"strings"
+	"syscall/js"
+	"testing"
+)
+
+// The tests call the bindings directly, as JavaScript does, and need a
+// JavaScript host: run them with
+//
+//	GOOS=js GOARCH=wasm go test -exec="$(go env GOROOT)/lib/wasm/go_js_wasm_exec" ./cmd/wasm
+//
+// or "make test".
+
+func TestMain(m *testing.M) {
+	if err := registerModifiers(); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// binding is the signature of the functions bound to JavaScript.
+type binding func(this js.Value, args []js.Value) any
+
+// call invokes a binding with args converted by js.ValueOf and returns its
+// response map.
+func call(t *testing.T, fn binding, args ...any) map[string]any {
+	t.Helper()
+	values := make([]js.Value, len(args))
+	for i, arg := range args {
+		values[i] = js.ValueOf(arg)
+	}
+	response, ok := fn(js.Undefined(), values).(map[string]any)
+	if !ok {
+		t.Fatalf("binding returned %T, want a response map", fn(js.Undefined(), values))
+	}
+	return response
+}
+
+// mustResult invokes a binding returning a result field, such as the
+// mutation and conversion bindings, and fails the test on an error
+// response.
+func mustResult(t *testing.T, fn binding, args ...any) string {
+	t.Helper()
+	response := call(t, fn, args...)
+	if message, failed := response["error"]; failed {
+		t.Fatalf("unexpected error %q (code %v)", message, response["code"])
+	}
+	return response["result"].(string)
+}
+
+// mustQuery runs executeQuery and fails the test on an error response.
+func mustQuery(t *testing.T, xml, path string, options ...any) map[string]any {
+	t.Helper()
+	response := call(t, executeQuery, append([]any{xml, path}, options...)...)
+	if message, failed := response["error"]; failed {
+		t.Fatalf("%s: unexpected error %q (code %v)", path, message, response["code"])
+	}
+	return response
+}
+
+func TestBOMAndDeclaredEncodings(t *testing.T) {
+	fixtures := []struct {
+		name   string
+		prefix string // BOM and declaration before the document element
+	}{
+		{name: "BOM", prefix: utf8BOM},
+		{name: "BOM and UTF-8 declaration", prefix: utf8BOM + `<?xml version="1.0" encoding="UTF-8"?>`},
+		{name: "BOM and UTF-16 declaration", prefix: utf8BOM + `<?xml version="1.0" encoding="UTF-16"?>` + "\n"},
+		{name: "UTF-16 declaration", prefix: `<?xml version="1.0" encoding="UTF-16"?>`},
+		{name: "windows-1252 declaration", prefix: `<?xml version="1.0" encoding="windows-1252"?>`},
+		{name: "lower case ISO-8859-1 declaration", prefix: `<?xml version="1.0" encoding='iso-8859-1'?>`},
+	}
+	for _, f := range fixtures {
+		t.Run(f.name, func(t *testing.T) {
+			xml := f.prefix + "<r><a>é</a></r>"
+			// Output keeps the declaration but not the BOM
+			declaration := strings.TrimPrefix(f.prefix, utf8BOM)
+
+			if valid := validateXML(js.Undefined(), []js.Value{js.ValueOf(xml)}); valid != true {
+				t.Errorf("validateXML = %v, want true", valid)
+			}
+			if response := mustQuery(t, xml, "r.a"); response["value"] != "é" || response["path"] != "r.a" {
+				t.Errorf("executeQuery = %v, want é at r.a", response)
+			}
+			if got := mustResult(t, minifyXML, xml); got != strings.TrimSpace(declaration)+"<r><a>é</a></r>" {
+				t.Errorf("minifyXML = %q", got)
+			}
+			if got := mustResult(t, prettifyXML, xml, " "); !strings.HasSuffix(got, "<r>\n <a>é</a>\n</r>") {
+				t.Errorf("prettifyXML = %q", got)
+			}
+			if got := mustResult(t, xmlToJSON, xml); got != `{"r":{"a":"é"}}` {
+				t.Errorf("xmlToJSON = %q", got)
+			}
+			if got := mustResult(t, setValue, xml, "r.a", "b"); got != declaration+"<r><a>b</a></r>" {
+				t.Errorf("setValue = %q", got)
+			}
+			if got := mustResult(t, deleteNode, xml, "r.a"); got != declaration+"<r></r>" {
+				t.Errorf("deleteNode = %q", got)
+			}
+		})
+	}
+}
+
+func TestUnsupportedEncoding(t *testing.T) {
+	xml := utf8BOM + "<?xml version=\"1.0\"\nencoding=\"Shift_JIS\"?><r><a>x</a></r>"
+	const message = "unsupported encoding 'Shift_JIS'"
+
+	if valid := validateXML(js.Undefined(), []js.Value{js.ValueOf(xml)}); valid != false {
+		t.Errorf("validateXML = %v, want false", valid)
+	}
+	detailed := call(t, validateXMLDetailed, xml)
+	if detailed["valid"] != false || detailed["message"] != message || detailed["line"] != 2 || detailed["column"] != 10 {
+		t.Errorf("validateXMLDetailed = %v, want %q at 2:10", detailed, message)
+	}
+	responses := map[string]map[string]any{
+		"executeQuery":   call(t, executeQuery, xml, "r.a"),
+		"executeQueries": call(t, executeQueries, xml, []any{"r.a"}),
+		"setValue":       call(t, setValue, xml, "r.a", "y"),
+		"prettifyXML":    call(t, prettifyXML, xml),
+		"xmlToJSON":      call(t, xmlToJSON, xml),
+		"listNamespaces": call(t, listNamespaces, xml),
+		"diffXML":        call(t, diffXML, xml, "<r/>"),
+	}
+	for name, response := range responses {
+		if response["code"] != codeMalformed {
+			t.Errorf("%s = %v, want a malformed error", name, response)
+		}
+	}
+}
+
+// resetCaches empties the query cache and the parsed outline, so the next
+// query starts cold.
+func resetCaches() {
+	queries.cacheKey("", "", defaultQueryConfig())
+	parsedOutline.doc, parsedOutline.root, parsedOutline.err = "", nil, nil
+}
+
+// smallConfig is a document of the size the playground queries on every
+// keystroke.
+const smallConfig = `<config><system><hostname>r1</hostname></system><interfaces>` +
+	`<interface><name>Gi0/0</name><mtu>1500</mtu></interface>` +
+	`<interface><name>Gi0/1</name><mtu>9000</mtu></interface>` +
+	`</interfaces></config>`
+
+// BenchmarkExecuteQuery reports the allocations of a query in a tight loop,
+// evaluated afresh and answered from the query cache.
+func BenchmarkExecuteQuery(b *testing.B) {
+	args := []js.Value{js.ValueOf(smallConfig), js.ValueOf("config.interfaces.interface.1.name")}
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			resetCaches()
+			executeQuery(js.Undefined(), args)
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		resetCaches()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			executeQuery(js.Undefined(), args)
+		}
+	})
+}
@@ -0,0 +1,256 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/netascode/xmldot"
+)
+
+// registerModifiers adds the playground's modifiers to xmldot's global
+// registry so they work in any query pipeline, e.g. "items.item|@reverse|@this".
+func registerModifiers() error {
+	modifiers := []xmldot.Modifier{
+		// @this returns the result unchanged, as in GJSON
+		xmldot.NewModifierFunc("this", func(r xmldot.Result) xmldot.Result {
+			return r
+		}),
+		xmldot.NewModifierFunc("keys", keysModifier),
+		xmldot.NewModifierFunc("values", valuesModifier),
+		// Variants of the built-in @sort, which picks numeric or string order itself
+		xmldot.NewModifierFunc("sort:num", sortModifier(true, false)),
+		xmldot.NewModifierFunc("sort:desc", sortModifier(false, true)),
+		xmldot.NewModifierFunc("sort:num:desc", sortModifier(true, true)),
+		xmldot.NewModifierFunc("flatten:deep", deepFlattenModifier),
+		xmldot.NewModifierFunc("number", coerceModifier(numberValue)),
+		xmldot.NewModifierFunc("string", coerceModifier(stringValue)),
+		xmldot.NewModifierFunc("bool", coerceModifier(boolValue)),
+	}
+
+	for _, m := range modifiers {
+		if err := xmldot.RegisterModifier(m.Name(), m); err != nil {
+			return fmt.Errorf("register modifier @%s: %w", m.Name(), err)
+		}
+	}
+	return nil
+}
+
+// keysModifier returns an Array of the tag names of an Element result's
+// child elements in document order. Text between child elements is not a
+// key. Non-element results yield Null.
+func keysModifier(r xmldot.Result) xmldot.Result {
+	children, ok := childElements(r)
+	if !ok {
+		return xmldot.Result{}
+	}
+
+	keys := xmldot.Result{Type: xmldot.Array, Results: []xmldot.Result{}}
+	for i, child := range children {
+		keys.Results = append(keys.Results, xmldot.Result{Type: xmldot.String, Raw: child.name, Str: child.name, Index: i})
+	}
+	return keys
+}
+
+// valuesModifier returns an Array of the text values of an Element result's
+// child elements in document order. Text directly inside the element (mixed
+// content) is excluded, matching @keys. An Array result is mapped element by
+// element into an Array of such Arrays, which @flatten collapses into one
+// list. Other results yield Null.
+func valuesModifier(r xmldot.Result) xmldot.Result {
+	if r.Type == xmldot.Array {
+		values := xmldot.Result{Type: xmldot.Array, Results: []xmldot.Result{}}
+		for i, item := range r.Results {
+			if item = valuesModifier(item); item.Type != xmldot.Null {
+				item.Index = i
+				values.Results = append(values.Results, item)
+			}
+		}
+		return values
+	}
+
+	children, ok := childElements(r)
+	if !ok {
+		return xmldot.Result{}
+	}
+
+	values := xmldot.Result{Type: xmldot.Array, Results: []xmldot.Result{}}
+	for i, child := range children {
+		inner := r.Raw[child.innerStart:child.innerEnd]
+		values.Results = append(values.Results, xmldot.Result{Type: xmldot.Element, Raw: inner, Str: charData(inner), Index: i})
+	}
+	return values
+}
+
+// deepFlattenModifier collapses nested Array results at any depth into a
+// single Array in document order, where the built-in @flatten removes one
+// level only. Like @flatten, it yields Null when nothing is left and returns
+// other results unchanged.
+func deepFlattenModifier(r xmldot.Result) xmldot.Result {
+	if r.Type != xmldot.Array {
+		return r
+	}
+
+	var flattened []xmldot.Result
+	var collect func(items []xmldot.Result)
+	collect = func(items []xmldot.Result) {
+		for _, item := range items {
+			if item.Type == xmldot.Array {
+				collect(item.Results)
+			} else {
+				flattened = append(flattened, item)
+			}
+		}
+	}
+	collect(r.Results)
+
+	if len(flattened) == 0 {
+		return xmldot.Result{}
+	}
+	return xmldot.Result{Type: xmldot.Array, Results: flattened}
+}
+
+// sortModifier returns a stable sort of an Array result: by String() when
+// numeric is false, and by numeric value otherwise, with values that are not
+// numbers placed after all numbers in their original order. Equal values
+// keep their original order in both directions. Other results are returned
+// unchanged, as with @sort.
+func sortModifier(numeric, descending bool) func(xmldot.Result) xmldot.Result {
+	return func(r xmldot.Result) xmldot.Result {
+		if r.Type != xmldot.Array || len(r.Results) <= 1 {
+			return r
+		}
+
+		sorted := make([]xmldot.Result, len(r.Results))
+		copy(sorted, r.Results)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			if numeric {
+				a, aOK := numericValue(sorted[i])
+				b, bOK := numericValue(sorted[j])
+				if !aOK || !bOK {
+					return aOK && !bOK
+				}
+				if descending {
+					return a > b
+				}
+				return a < b
+			}
+			if descending {
+				return sorted[i].String() > sorted[j].String()
+			}
+			return sorted[i].String() < sorted[j].String()
+		})
+		return xmldot.Result{Type: xmldot.Array, Results: sorted}
+	}
+}
+
+// coerceModifier returns a modifier converting a result with coerce, item by
+// item for an Array result. Values that cannot be converted become Null, as
+// do Null results.
+func coerceModifier(coerce func(xmldot.Result) xmldot.Result) func(xmldot.Result) xmldot.Result {
+	return func(r xmldot.Result) xmldot.Result {
+		if r.Type == xmldot.Array {
+			results := make([]xmldot.Result, len(r.Results))
+			for i, item := range r.Results {
+				results[i] = coerce(item)
+				results[i].Index = item.Index
+			}
+			return xmldot.Result{Type: xmldot.Array, Results: results}
+		}
+		if r.Type == xmldot.Null {
+			return r
+		}
+		coerced := coerce(r)
+		coerced.Index = r.Index
+		return coerced
+	}
+}
+
+// numberValue converts a result to a Number: text is trimmed and parsed,
+// and booleans become 1 or 0. Infinities and NaN are not numbers here.
+func numberValue(r xmldot.Result) xmldot.Result {
+	var value float64
+	switch r.Type {
+	case xmldot.True:
+		value = 1
+	case xmldot.False:
+		value = 0
+	default:
+		var ok bool
+		if value, ok = numericValue(r); !ok || math.IsInf(value, 0) || math.IsNaN(value) {
+			return xmldot.Result{}
+		}
+	}
+	formatted := strconv.FormatFloat(value, 'f', -1, 64)
+	return xmldot.Result{Type: xmldot.Number, Num: value, Raw: formatted, Str: formatted}
+}
+
+// stringValue converts a result to a String of its trimmed text. Numbers
+// and booleans are written as in JSON.
+func stringValue(r xmldot.Result) xmldot.Result {
+	var value string
+	switch r.Type {
+	case xmldot.Number:
+		value = strconv.FormatFloat(r.Num, 'f', -1, 64)
+	case xmldot.True:
+		value = "true"
+	case xmldot.False:
+		value = "false"
+	default:
+		value = strings.TrimSpace(r.String())
+	}
+	return xmldot.Result{Type: xmldot.String, Raw: value, Str: value}
+}
+
+// boolValue converts a result to True or False. Text is trimmed and must be
+// "true", "yes" or "1", or "false", "no" or "0", in any case; numbers are
+// true unless zero.
+func boolValue(r xmldot.Result) xmldot.Result {
+	var value bool
+	switch r.Type {
+	case xmldot.True, xmldot.False:
+		return r
+	case xmldot.Number:
+		value = r.Num != 0
+	default:
+		switch strings.ToLower(strings.TrimSpace(r.String())) {
+		case "true", "yes", "1":
+			value = true
+		case "false", "no", "0":
+			value = false
+		default:
+			return xmldot.Result{}
+		}
+	}
+	if value {
+		return xmldot.Result{Type: xmldot.True, Raw: "true", Str: "true"}
+	}
+	return xmldot.Result{Type: xmldot.False, Raw: "false", Str: "false"}
+}
+
+// numericValue returns the value of a Number result, or of text that parses
+// as a number.
+func numericValue(r xmldot.Result) (float64, bool) {
+	if r.Type == xmldot.Number {
+		return r.Num, true
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(r.String()), 64)
+	return value, err == nil
+}
+
+// childElements parses the content of an Element result into its child
+// elements. Offsets of the returned nodes index into r.Raw.
+func childElements(r xmldot.Result) ([]*node, bool) {
+	if r.Type != xmldot.Element {
+		return nil, false
+	}
+	content, err := parseOutline(r.Raw)
+	if err != nil {
+		return nil, false
+	}
+	return content.children, true
+}
@@ -0,0 +1,195 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/netascode/xmldot"
+)
+
+// multipathField is one entry of a multipath query.
+type multipathField struct {
+	key  string
+	path string
+}
+
+// isMultipath reports whether path uses the multipath form "{a,b,c}".
+func isMultipath(path string) bool {
+	return strings.HasPrefix(path, "{")
+}
+
+// runMultipath evaluates a multipath query such as
+// "{interfaces.interface.name,mtu:interfaces.interface.mtu}" and returns
+// a composite result. Each entry is either a path, keyed by its last
+// segment, or a labelled "key:path" (the key may be quoted). Because the
+// first colon introduces a label, a namespaced path needs an explicit one.
+// Missing paths produce null entries rather than failing the query.
+// The value is a JSON object; results lists each field with its key.
+func runMultipath(xml, path string, entities map[string]string, config queryConfig) map[string]any {
+	fields, err := parseMultipath(path)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	items := make([]any, 0, len(fields))
+	var object strings.Builder
+	object.WriteByte('{')
+	for i, field := range fields {
+		if err := checkDeadline(); err != nil {
+			return errorResponse(err)
+		}
+		eval, err := evaluate(xml, field.path, config.opts)
+		if err != nil {
+			return errorResponse(err)
+		}
+		if config.expandReferences {
+			if eval.result, err = expandReferences(xml, eval, entities); err != nil {
+				return errorResponse(err)
+			}
+		}
+		if config.normalizeSpace {
+			eval.result = normalizeResult(eval.result)
+		}
+
+		if i > 0 {
+			object.WriteByte(',')
+		}
+		writeJSONString(&object, field.key)
+		object.WriteByte(':')
+		object.WriteString(resultJSON(eval.result))
+
+		item := resultToMap(eval.result)
+		item["key"] = field.key
+		items = append(items, item)
+	}
+	object.WriteByte('}')
+
+	return map[string]any{
+		"value":   object.String(),
+		"raw":     "",
+		"exists":  true,
+		"type":    "Object",
+		"index":   0,
+		"results": items,
+	}
+}
+
+// parseMultipath splits "{...}" into its fields.
+func parseMultipath(path string) ([]multipathField, error) {
+	if !strings.HasSuffix(path, "}") || len(path) < 2 {
+		return nil, newError(codeInvalidPath, "Multipath query must be enclosed in { }")
+	}
+
+	entries := splitTopLevel(path[1:len(path)-1], ',')
+	if len(entries) > MaxMultipathFields {
+		return nil, newError(codeTooLarge, "Too many multipath fields (%d, max %d)", len(entries), MaxMultipathFields)
+	}
+
+	fields := make([]multipathField, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			return nil, newError(codeInvalidPath, "Multipath query contains an empty field")
+		}
+
+		field := multipathField{path: entry}
+		if key, rest, ok := cutLabel(entry); ok {
+			field = multipathField{key: key, path: strings.TrimSpace(rest)}
+		} else {
+			_, last, _ := cutLastSegment(entry)
+			field.key = strings.ReplaceAll(last, "\\", "")
+		}
+		if field.path == "" || isMultipath(field.path) {
+			return nil, newError(codeInvalidPath, "Invalid multipath field %q", entry)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// cutLabel splits a "key:path" or "\"key\":path" multipath entry.
+func cutLabel(entry string) (key, rest string, ok bool) {
+	if strings.HasPrefix(entry, `"`) {
+		end := strings.Index(entry[1:], `"`)
+		if end < 0 || !strings.HasPrefix(entry[end+2:], ":") {
+			return "", "", false
+		}
+		return entry[1 : end+1], entry[end+3:], true
+	}
+
+	key, rest, found := strings.Cut(entry, ":")
+	if !found || !isLabel(key) {
+		return "", "", false
+	}
+	return key, rest, true
+}
+
+// isLabel reports whether s can be used as an unquoted multipath key.
+func isLabel(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !(c == '_' || c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// splitTopLevel splits s on sep outside parentheses, braces, quotes and
+// escape sequences.
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	depth := 0
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(' || c == '{':
+			depth++
+		case c == ')' || c == '}':
+			if depth > 0 {
+				depth--
+			}
+		case c == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// resultJSON encodes a result as a JSON value: null for missing values,
+// booleans and numbers by type, arrays element-wise and strings otherwise.
+func resultJSON(r xmldot.Result) string {
+	switch r.Type {
+	case xmldot.Null:
+		return "null"
+	case xmldot.True:
+		return "true"
+	case xmldot.False:
+		return "false"
+	case xmldot.Number:
+		return strconv.FormatFloat(r.Num, 'f', -1, 64)
+	case xmldot.Array:
+		items := make([]string, len(r.Results))
+		for i, item := range r.Results {
+			items[i] = resultJSON(item)
+		}
+		return "[" + strings.Join(items, ",") + "]"
+	default:
+		return jsonString(r.String())
+	}
+}
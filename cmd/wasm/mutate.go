@@ -0,0 +1,507 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"syscall/js"
+	"time"
+
+	"github.com/netascode/xmldot"
+)
+
+// setValue sets the value at path and returns the modified document.
+// Elements and attributes along the path are created as needed, new
+// elements as the last child of their parent unless an insertion hint
+// places them. A final "-1" index appends a new element, as in xmldot's
+// Set, and so does a "name[+]" step anywhere in the path (see
+// appendPosition).
+// Self-closing and open/close elements are treated alike (see setContent).
+// Unlike deleteNode, setValue changes a single node: a path matching
+// several elements is rejected rather than set at the first match only, so
+// one must be selected with an index, position or filter.
+// Args: xml (string), path (string), value (string, number or boolean),
+// options (optional object: before or after, the name of a sibling to place
+// a newly created final element next to)
+// Returns: map with result field OR error field
+func setValue(this js.Value, args []js.Value) (result any) {
+	// Panic recovery with safe error return
+	defer func() {
+		if r := recover(); r != nil {
+			result = makeError(codeInternal, "Set failed due to resource limits or invalid input")
+		}
+	}()
+
+	// Validate argument count
+	if len(args) != 3 && len(args) != 4 {
+		return makeError(codeInvalidArgument, "Expected 3 or 4 arguments: xml, path, value and optional options")
+	}
+
+	xml, path, errResult := queryArgs(args[0], args[1])
+	if errResult != nil {
+		return errResult
+	}
+
+	// Mutations drop a leading byte order mark, as the formatting bindings do
+	source := trimBOM(xml)
+	xml, _, doctypeErr := readDoctype(source)
+	if doctypeErr != nil {
+		return makeError(codeMalformed, "Invalid DOCTYPE declaration: "+doctypeErr.Message)
+	}
+
+	var value any
+	switch args[2].Type() {
+	case js.TypeString:
+		value = args[2].String()
+	case js.TypeNumber:
+		value = args[2].Float()
+	case js.TypeBoolean:
+		value = args[2].Bool()
+	default:
+		return makeError(codeInvalidArgument, "Third argument (value) must be a string, number or boolean")
+	}
+
+	hint, err := setInsertionHint(args)
+	if err != nil {
+		return makeError(codeInvalidArgument, err.Error())
+	}
+
+	modified, err := setPath(xml, path, value, hint)
+	if err != nil {
+		return makeError(codeInvalidArgument, fmt.Sprintf("Set failed: %v", err))
+	}
+	return mutationResult(restoreSubset(source, xml, modified))
+}
+
+// setPath sets value at path in xml for setValue.
+func setPath(xml, path string, value any, hint insertionHint) (string, error) {
+	segments, _ := splitRawPath(path)
+	for i, segment := range segments {
+		if base, position, ok := cutPosition(segment); ok && strings.TrimSpace(position) == appendPosition {
+			return appendElement(xml, segments, i, base, value)
+		}
+	}
+
+	elementSegments, attribute := segments, ""
+	if last := segments[len(segments)-1]; strings.HasPrefix(last, "@") {
+		elementSegments, attribute = segments[:len(segments)-1], last[1:]
+	}
+	if len(elementSegments) > 0 {
+		target, err := setTarget(xml, elementSegments)
+		if err != nil {
+			return "", err
+		}
+		if target != nil && !hasNegativeIndex(elementSegments) {
+			if attribute != "" {
+				return setAttribute(xml, target, unescapePath(attribute), value)
+			}
+			return setContent(xml, target, value)
+		}
+	}
+
+	parentPath, last, _ := cutLastSegment(path)
+
+	parent, parentFound := resolveElement(xml, parentPath, xmldot.DefaultOptions())
+	if element, ok := resolveElement(xml, path, xmldot.DefaultOptions()); ok {
+		return setContent(xml, element, value)
+	} else if parentFound && strings.HasPrefix(last, "@") {
+		return setAttribute(xml, parent, unescapePath(last[1:]), value)
+	} else if sibling, ok := hint.sibling(parent); parentFound && ok && isPlainName(last) {
+		element, err := newElement(unescapePath(last), value)
+		if err != nil {
+			return "", err
+		}
+		return insertNextTo(xml, sibling, hint.after, element), nil
+	}
+	return xmldot.Set(openSelfClosing(xml, path), path, value)
+}
+
+// setTarget finds the element that the element steps of a Set path select,
+// when it exists, so filters and positions can be set as well as queried.
+// It returns an error when the deepest existing part of the path matches
+// more than one element: xmldot's Set only changes the first match, and
+// setValue does not guess which was meant.
+func setTarget(xml string, segments []string) (*node, error) {
+	for i := len(segments); i > 0; i-- {
+		nodes, err := navigationContext(xml, segments[:i], xmldot.DefaultOptions())
+		if err != nil || len(nodes) == 0 {
+			continue
+		}
+		if len(nodes) > 1 {
+			return nil, fmt.Errorf("%s matches %d elements; select one with an index, position or filter", strings.Join(segments[:i], "."), len(nodes))
+		}
+		if i < len(segments) {
+			return nil, nil
+		}
+		return nodes[0], nil
+	}
+	return nil, nil
+}
+
+// hasNegativeIndex reports whether segments use a negative index, which
+// Set reads as appending rather than as counting from the end.
+func hasNegativeIndex(segments []string) bool {
+	for _, segment := range segments {
+		if isIndex(segment) && strings.HasPrefix(segment, "-") {
+			return true
+		}
+	}
+	return false
+}
+
+// appendPosition is the position of a Set step that adds a new element
+// rather than selecting one: "interfaces.interface[+].name" appends an
+// interface after the last existing one and sets its name. The path before
+// the step must lead to an existing element, as resolveElement follows it
+// (names and indexes, no filters), and the step must name an element; the
+// rest of the path is set inside the new element. xmldot's "-1" index
+// appends as well, but only as the final step. Queries reject "[+]" as an
+// invalid position.
+const appendPosition = "+"
+
+// appendElement evaluates the "name[+]" step segments[i] of a Set path.
+func appendElement(xml string, segments []string, i int, name string, value any) (string, error) {
+	if !isPlainName(name) {
+		return "", fmt.Errorf("[%s] must follow an element name, not %q", appendPosition, name)
+	}
+	if i == 0 {
+		return "", fmt.Errorf("[%s] cannot add a second document element", appendPosition)
+	}
+	parentPath := strings.Join(segments[:i], ".")
+	parent, ok := resolveElement(xml, parentPath, xmldot.DefaultOptions())
+	if !ok {
+		return "", fmt.Errorf("[%s] appends to an existing element, and %s was not found", appendPosition, parentPath)
+	}
+
+	// The new element is built on its own, so the rest of the path may
+	// create attributes and children in it, or append again
+	local := unescapePath(name)
+	element, err := setPath("<"+local+"></"+local+">", strings.Join(append([]string{name}, segments[i+1:]...), "."), value, insertionHint{})
+	if err != nil {
+		return "", err
+	}
+
+	siblings := parent.childrenNamed(local, xmldot.DefaultOptions())
+	if len(siblings) == 0 {
+		siblings = parent.children
+	}
+	if len(siblings) > 0 {
+		return insertNextTo(xml, siblings[len(siblings)-1], true, element), nil
+	}
+	startTag := xml[parent.start:parent.innerStart]
+	if strings.HasSuffix(startTag, "/>") {
+		startTag = strings.TrimRight(strings.TrimSuffix(startTag, "/>"), " \t\r\n") + ">"
+		return xml[:parent.start] + startTag + element + "</" + parent.name + ">" + xml[parent.end:], nil
+	}
+	return xml[:parent.innerEnd] + element + xml[parent.innerEnd:], nil
+}
+
+// setContent replaces the content of an existing element with value,
+// formatted and escaped as xmldot's Set writes it. xmldot's Set writes
+// content after the "/>" of a self-closing element and misplaces its edits
+// when an earlier start tag has whitespace before its closing ">", so
+// elements the outline can locate are edited here instead. A self-closing
+// element is rewritten as "<x>value</x>"; an empty value on an empty element
+// keeps the form the document uses.
+func setContent(xml string, element *node, value any) (string, error) {
+	formatted, err := xmldot.Set("<x></x>", "x", value)
+	if err != nil {
+		return "", err
+	}
+	content := strings.TrimSuffix(strings.TrimPrefix(formatted, "<x>"), "</x>")
+
+	startTag := xml[element.start:element.innerStart]
+	if !strings.HasSuffix(startTag, "/>") {
+		return xml[:element.innerStart] + content + xml[element.innerEnd:], nil
+	}
+	if content == "" {
+		return xml, nil
+	}
+	startTag = strings.TrimRight(strings.TrimSuffix(startTag, "/>"), " \t\r\n") + ">"
+	return xml[:element.start] + startTag + content + "</" + element.name + ">" + xml[element.end:], nil
+}
+
+// insertionHint places an element that setValue creates next to a named
+// sibling (see insertNextTo): after the last sibling of that name, or
+// before the first.
+type insertionHint struct {
+	name  string
+	after bool
+}
+
+// setInsertionHint reads the optional fourth argument of setValue, an
+// object with a before or after field naming a sibling. Undefined or null
+// selects the default, appending.
+func setInsertionHint(args []js.Value) (insertionHint, error) {
+	if len(args) < 4 || args[3].IsUndefined() || args[3].IsNull() {
+		return insertionHint{}, nil
+	}
+	if args[3].Type() != js.TypeObject {
+		return insertionHint{}, fmt.Errorf("Fourth argument (options) must be an object")
+	}
+	before, after := args[3].Get("before"), args[3].Get("after")
+	if !before.IsUndefined() && !after.IsUndefined() {
+		return insertionHint{}, fmt.Errorf("Options before and after cannot be combined")
+	}
+	hint, value := insertionHint{after: !after.IsUndefined()}, before
+	if hint.after {
+		value = after
+	}
+	if value.IsUndefined() {
+		return hint, nil
+	}
+	if value.Type() != js.TypeString || value.String() == "" {
+		return insertionHint{}, fmt.Errorf("Options before and after must name an element")
+	}
+	hint.name = value.String()
+	return hint, nil
+}
+
+// sibling returns the child of parent that the hint places a new element
+// next to. It reports false without a hint, or when parent has no child of
+// that name, in which case the element is appended as usual.
+func (h insertionHint) sibling(parent *node) (*node, bool) {
+	if h.name == "" || parent == nil {
+		return nil, false
+	}
+	siblings := parent.childrenNamed(h.name, xmldot.DefaultOptions())
+	if len(siblings) == 0 {
+		return nil, false
+	}
+	if h.after {
+		return siblings[len(siblings)-1], true
+	}
+	return siblings[0], true
+}
+
+// newElement formats a new element holding value. The content is
+// formatted by xmldot's Set on a scratch element, as in setContent.
+func newElement(name string, value any) (string, error) {
+	if !isXMLName(name) {
+		return "", fmt.Errorf("invalid element name %q", name)
+	}
+	formatted, err := xmldot.Set("<x></x>", "x", value)
+	if err != nil {
+		return "", err
+	}
+	return "<" + name + ">" + strings.TrimSuffix(strings.TrimPrefix(formatted, "<x>"), "</x>") + "</" + name + ">", nil
+}
+
+// insertNextTo writes element right after or before sibling, repeating the
+// whitespace before sibling so the new element is indented like it.
+func insertNextTo(xml string, sibling *node, after bool, element string) string {
+	indent := xml[:sibling.start]
+	indent = indent[len(strings.TrimRight(indent, " \t\r\n")):]
+	if after {
+		return xml[:sibling.end] + indent + element + xml[sibling.end:]
+	}
+	return xml[:sibling.start] + element + indent + xml[sibling.start:]
+}
+
+// setAttribute sets an attribute of an existing element, replacing the
+// attribute written with the same name or adding it after the last one.
+// The name="value" token is formatted by xmldot's Set on a scratch element,
+// for the same reasons setContent edits elements itself.
+func setAttribute(xml string, element *node, name string, value any) (string, error) {
+	formatted, err := xmldot.Set("<x></x>", "x.@"+name, value)
+	if err != nil {
+		return "", err
+	}
+	tokens := attributeTokens(formatted)
+	if len(tokens) != 1 {
+		return "", fmt.Errorf("invalid attribute name %q", name)
+	}
+	token := formatted[tokens[0].start:tokens[0].end]
+
+	startTag := xml[element.start:element.innerStart]
+	insertAt := 1 + len(element.name)
+	for _, existing := range attributeTokens(startTag) {
+		if existing.name == name {
+			return xml[:element.start+existing.start] + token + xml[element.start+existing.end:], nil
+		}
+		insertAt = existing.end
+	}
+	return xml[:element.start+insertAt] + " " + token + xml[element.start+insertAt:], nil
+}
+
+// openSelfClosing prepares a Set that creates new nodes: when the deepest
+// existing element along path is self-closing and path continues below it,
+// the element is rewritten as "<x></x>" so xmldot inserts the new children
+// inside it. Paths that resolveElement cannot follow are returned unchanged.
+func openSelfClosing(xml, path string) string {
+	segments, _ := splitRawPath(path)
+	if strings.HasPrefix(segments[len(segments)-1], "@") {
+		// Attributes are written into the start tag, whatever its form
+		segments = segments[:len(segments)-1]
+	}
+
+	for i := len(segments); i > 0; i-- {
+		element, ok := resolveElement(xml, strings.Join(segments[:i], "."), xmldot.DefaultOptions())
+		if !ok {
+			continue
+		}
+		startTag := xml[element.start:element.innerStart]
+		if i == len(segments) || isIndex(segments[i]) || !strings.HasSuffix(startTag, "/>") {
+			// An index past the last match adds a sibling, not a child
+			return xml
+		}
+		startTag = strings.TrimRight(strings.TrimSuffix(startTag, "/>"), " \t\r\n") + ">"
+		return xml[:element.start] + startTag + "</" + element.name + ">" + xml[element.end:]
+	}
+	return xml
+}
+
+// mutationResult checks a modified document against the size limit and for
+// well-formedness before returning it to JavaScript.
+func mutationResult(modified string) map[string]any {
+	if len(modified) > xmlSizeLimit {
+		return makeError(codeTooLarge, fmt.Sprintf("Resulting XML too large (%d bytes, max %d)", len(modified), xmlSizeLimit))
+	}
+	if masked, _, err := readDoctype(modified); err != nil || !xmldot.Valid(masked) {
+		return makeError(codeMalformed, "Resulting XML is not well-formed")
+	}
+	return map[string]any{
+		"result": modified,
+	}
+}
+
+// deleteNode removes the elements or attributes at path and returns the
+// modified document. A path of names removes every match, as
+// "config.users.user.@password" removes the attribute from each user; a
+// path selecting one node, by index, position or a "#(...)" first-match
+// filter, removes just that node. When nothing matches, the document is
+// returned unchanged and matched is false. At most MaxWildcardResults
+// matches are removed per call, with truncated set once that many were,
+// and the call has the default query time budget.
+// Args: xml (string), path (string)
+// Returns: map with result, matched and truncated fields OR error field
+func deleteNode(this js.Value, args []js.Value) (result any) {
+	// Panic recovery with safe error return
+	defer func() {
+		if r := recover(); r != nil {
+			result = makeError(codeInternal, "Delete failed due to resource limits or invalid input")
+		}
+	}()
+
+	// Validate argument count
+	if len(args) != 2 {
+		return makeError(codeInvalidArgument, "Expected 2 arguments: xml and path")
+	}
+
+	xml, path, errResult := queryArgs(args[0], args[1])
+	if errResult != nil {
+		return errResult
+	}
+
+	// xmldot's Delete rejects a leading byte order mark
+	source := trimBOM(xml)
+	xml, _, doctypeErr := readDoctype(source)
+	if doctypeErr != nil {
+		return makeError(codeMalformed, "Invalid DOCTYPE declaration: "+doctypeErr.Message)
+	}
+
+	return runWithTimeout(DefaultQueryTimeout*time.Millisecond, func() map[string]any {
+		modified, truncated, err := deleteAll(xml, path)
+		if err != nil {
+			return errorResponse(err)
+		}
+
+		response := mutationResult(restoreSubset(source, xml, modified))
+		if _, failed := response["error"]; !failed {
+			response["matched"] = modified != xml
+			if truncated {
+				response["truncated"] = true
+				response["matchLimit"] = xmldot.MaxWildcardResults
+			}
+		}
+		return response
+	})
+}
+
+// deleteAll removes the matches of path from xml. xmldot's Delete removes
+// only the first match, so the matching elements are found as
+// navigationContext finds them and cut out of the document in one pass:
+// each element's markup, or the name="value" token of the attribute with
+// the whitespace before it. At most the library's MaxWildcardResults
+// matches are removed; truncated reports reaching that limit, as more may
+// have matched. A path selecting a single node, or matching nothing in the
+// outline, is left to Delete.
+func deleteAll(xml, path string) (modified string, truncated bool, err error) {
+	if selectsOneNode(path) {
+		return deleteFirst(xml, path)
+	}
+
+	opts := xmldot.DefaultOptions()
+	segments, _ := splitRawPath(path)
+	attribute := ""
+	if last := segments[len(segments)-1]; strings.HasPrefix(last, "@") && len(segments) > 1 {
+		if !isPlainName(last[1:]) {
+			return deleteFirst(xml, path)
+		}
+		segments, attribute = segments[:len(segments)-1], last[1:]
+	}
+	nodes, err := navigationContext(xml, segments, opts)
+	if err != nil || len(nodes) == 0 {
+		// Delete may still resolve the path, unless the time budget ran out
+		if err := checkDeadline(); err != nil {
+			return "", false, err
+		}
+		return deleteFirst(xml, path)
+	}
+
+	var result strings.Builder
+	result.Grow(len(xml))
+	copied, removed := 0, 0
+	for _, n := range nodes {
+		if err := checkDeadline(); err != nil {
+			return "", false, err
+		}
+		if n.start < copied {
+			// Inside an element already removed
+			continue
+		}
+		cut := &span{n.start, n.end}
+		if attribute != "" {
+			if cut = n.attributeSpan(xml, attribute, opts); cut == nil {
+				continue
+			}
+			for cut.start > n.start && isXMLSpace(xml[cut.start-1]) {
+				cut.start--
+			}
+		}
+		if removed == xmldot.MaxWildcardResults {
+			break
+		}
+		result.WriteString(xml[copied:cut.start])
+		copied = cut.end
+		removed++
+	}
+	result.WriteString(xml[copied:])
+	return result.String(), removed == xmldot.MaxWildcardResults, nil
+}
+
+// deleteFirst removes the first match of path with the library's Delete.
+func deleteFirst(xml, path string) (string, bool, error) {
+	modified, err := xmldot.Delete(xml, path)
+	if err != nil {
+		return "", false, fmt.Errorf("Delete failed: %v", err)
+	}
+	return modified, false, nil
+}
+
+// selectsOneNode reports whether path picks one match by an index, a
+// position or a first-match "#(...)" filter, which Delete resolves as
+// queries do.
+func selectsOneNode(path string) bool {
+	segments, _ := splitRawPath(path)
+	for _, segment := range segments {
+		if _, _, positional := cutPosition(segment); positional || isIndex(segment) {
+			return true
+		}
+		if strings.HasPrefix(segment, "#(") && !strings.HasSuffix(segment, ")#") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/netascode/xmldot"
+)
+
+// mixedForms has empty elements in both forms among siblings of one name.
+const mixedForms = `<interfaces><interface/><interface></interface><interface>Gi0/1</interface></interfaces>`
+
+func TestEmptyElementFormsQueryAlike(t *testing.T) {
+	for _, path := range []string{"interfaces.interface.0", "interfaces.interface.1"} {
+		response := mustQuery(t, mixedForms, path)
+		if response["exists"] != true || response["value"] != "" || response["type"] != "Element" {
+			t.Errorf("%s: got exists=%v value=%q type=%v, want an existing empty Element",
+				path, response["exists"], response["value"], response["type"])
+		}
+		if exists := call(t, pathExists, mixedForms, path)["exists"]; exists != true {
+			t.Errorf("pathExists(%s) = %v, want true", path, exists)
+		}
+	}
+	if count := mustQuery(t, mixedForms, "interfaces.interface.#")["value"]; fmt.Sprint(count) != "3" {
+		t.Errorf("count = %v, want 3", count)
+	}
+}
+
+func TestSetValueOnMixedForms(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		value any
+		want  string
+	}{
+		{
+			name:  "self-closing gets content",
+			path:  "interfaces.interface.0",
+			value: "Gi0/0",
+			want:  `<interfaces><interface>Gi0/0</interface><interface></interface><interface>Gi0/1</interface></interfaces>`,
+		},
+		{
+			name:  "open/close gets content",
+			path:  "interfaces.interface.1",
+			value: "Gi0/2",
+			want:  `<interfaces><interface/><interface>Gi0/2</interface><interface>Gi0/1</interface></interfaces>`,
+		},
+		{
+			name:  "empty value keeps the self-closing form",
+			path:  "interfaces.interface.0",
+			value: "",
+			want:  mixedForms,
+		},
+		{
+			name:  "empty value keeps the open/close form",
+			path:  "interfaces.interface.1",
+			value: "",
+			want:  mixedForms,
+		},
+		{
+			name:  "attribute on a self-closing element",
+			path:  "interfaces.interface.0.@name",
+			value: "Gi0/0",
+			want:  `<interfaces><interface name="Gi0/0"/><interface></interface><interface>Gi0/1</interface></interfaces>`,
+		},
+		{
+			name:  "-1 appends as in xmldot's Set",
+			path:  "interfaces.interface.-1",
+			value: "Gi0/3",
+			want:  `<interfaces><interface/><interface></interface><interface>Gi0/1</interface><interface>Gi0/3</interface></interfaces>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mustResult(t, setValue, mixedForms, tt.path, tt.value); got != tt.want {
+				t.Errorf("setValue(%s, %q)\n got %s\nwant %s", tt.path, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetValueNegativeIndexOnlyAppendsLast(t *testing.T) {
+	response := call(t, setValue, mixedForms, "interfaces.interface.-1.@name", "x")
+	if response["code"] != codeInvalidArgument {
+		t.Errorf("got %v, want an invalidArgument error: xmldot only appends with a final -1", response)
+	}
+}
+
+// users has repeated elements and attributes for deleteNode.
+const users = `<config><users><user password="a"><n>1</n></user><user password="b"><n>2</n></user><user><n>3</n></user></users></config>`
+
+func TestDeleteNodeRemovesEveryMatch(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{
+			path: "config.users.user.@password",
+			want: `<config><users><user><n>1</n></user><user><n>2</n></user><user><n>3</n></user></users></config>`,
+		},
+		{
+			path: "config.users.user",
+			want: `<config><users></users></config>`,
+		},
+		{
+			path: "**.n",
+			want: `<config><users><user password="a"></user><user password="b"></user><user></user></users></config>`,
+		},
+		{
+			path: "config.users.user.#(n>1)#",
+			want: `<config><users><user password="a"><n>1</n></user></users></config>`,
+		},
+		{
+			path: "config.users.user.1",
+			want: `<config><users><user password="a"><n>1</n></user><user><n>3</n></user></users></config>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			response := call(t, deleteNode, users, tt.path)
+			if response["result"] != tt.want || response["matched"] != true {
+				t.Errorf("got %v\nwant %s", response, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeleteNodeWithoutMatch(t *testing.T) {
+	response := call(t, deleteNode, users, "config.users.user.@missing")
+	if response["result"] != users || response["matched"] != false {
+		t.Errorf("got %v, want the document unchanged and matched false", response)
+	}
+}
+
+func TestDeleteNodeMatchLimit(t *testing.T) {
+	xml := "<r>" + strings.Repeat(`<u pw="x"><n>1</n></u>`, xmldot.MaxWildcardResults+1) + "</r>"
+	tests := []struct {
+		path   string
+		marker string // occurs once per match
+	}{
+		{path: "r.u", marker: "<u"},
+		{path: "r.u.@pw", marker: "pw="},
+		{path: "**.n", marker: "<n>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			response := call(t, deleteNode, xml, tt.path)
+			if response["truncated"] != true || response["matched"] != true {
+				t.Fatalf("got %v, want matched and truncated", response)
+			}
+			if left := strings.Count(response["result"].(string), tt.marker); left != 1 {
+				t.Errorf("%d matches left, want 1", left)
+			}
+		})
+	}
+}
+
+func TestSetValueRejectsSeveralMatches(t *testing.T) {
+	for _, path := range []string{"config.users.user", "config.users.user.n", "config.users.user.@password", "config.users.user.email"} {
+		if response := call(t, setValue, users, path, "x"); response["code"] != codeInvalidArgument {
+			t.Errorf("%s: got %v, want an invalidArgument error", path, response)
+		}
+	}
+
+	want := `<config><users><user password="a"><n>1</n></user><user password="x"><n>2</n></user><user><n>3</n></user></users></config>`
+	for _, path := range []string{"config.users.user.1.@password", "config.users.user.#(n==2).@password", "config.users.user[2].@password"} {
+		if got := mustResult(t, setValue, users, path, "x"); got != want {
+			t.Errorf("%s:\n got %s\nwant %s", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,554 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall/js"
+
+	"github.com/netascode/xmldot"
+)
+
+// executeQueryWithNamespaces executes a query whose prefixes are bound to
+// namespace URIs by the caller, so documents using a different prefix for the
+// same URI still match. Binding a prefix to the URI of a default namespace
+// queries the unprefixed elements in it (see boundElements). Prefixes in
+// "#(...)" filter conditions are bound too. Unprefixed names are matched as
+// executeQuery matches them.
+// Args: xml (string), path (string), namespaces (object of prefix -> URI)
+// Returns: same shape as executeQuery OR error field
+func executeQueryWithNamespaces(this js.Value, args []js.Value) (result any) {
+	// Panic recovery with safe error return
+	defer func() {
+		if r := recover(); r != nil {
+			result = makeError(codeInternal, "Query execution failed due to resource limits or invalid input")
+		}
+	}()
+
+	// Validate argument count
+	if len(args) != 3 {
+		return makeError(codeInvalidArgument, "Expected 3 arguments: xml, path and namespaces")
+	}
+
+	xml, path, errResult := queryArgs(args[0], args[1])
+	if errResult != nil {
+		return errResult
+	}
+
+	bindings, err := namespaceBindings(args[2])
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	root, err := parseOutline(xml)
+	if err != nil {
+		return makeError(codeMalformed, "Invalid XML document")
+	}
+
+	config := defaultQueryConfig()
+	segments, modifiers := splitRawPath(path)
+	nodes, steps, err := boundElements(root, segments, bindings, config.opts)
+	if err != nil {
+		return errorResponse(err)
+	}
+	if steps > 0 {
+		return runFromElements(xml, nodes, segments[steps:], modifiers, bindings, root.namespaceDecls(), config)
+	}
+
+	boundPath, matchable, err := bindNamespaces(path, bindings, root.namespaceDecls())
+	if err != nil {
+		return errorResponse(err)
+	}
+	if !matchable {
+		// A bound URI is not declared in the document, so nothing can match
+		return resultToMap(xmldot.Result{})
+	}
+
+	return runQuery(xml, boundPath, config)
+}
+
+// boundElements matches the leading element steps of a namespace-bound
+// query on the outline, so a prefixed step selects exactly the elements in
+// its bound namespace, whatever prefix the document writes them with and
+// wherever it declares the URI. That includes elements in a default
+// namespace declared partway down the tree: bind any prefix to its URI and
+// "c:interface" selects the unprefixed <interface> elements in scope of
+// xmlns="urn:...", but not those outside it. Unprefixed steps keep the
+// library's matching by local name, and integer steps pick from all matches
+// so far, as the library does.
+// It returns the matched elements in document order and the number of
+// steps consumed: steps of element names and indexes up to the first other
+// step, or zero when none of them is prefixed and namespace-aware matching
+// is not needed.
+func boundElements(root *node, segments []string, bindings map[string]string, opts *xmldot.Options) ([]*node, int, error) {
+	steps, prefixed := 0, false
+	for ; steps < len(segments); steps++ {
+		segment := segments[steps]
+		if isIndex(segment) {
+			continue
+		}
+		if !isPlainName(segment) || strings.ContainsAny(segment, "[\\") {
+			break
+		}
+		prefixed = prefixed || strings.Contains(segment, ":")
+	}
+	if !prefixed {
+		return nil, 0, nil
+	}
+
+	nodes := []*node{root}
+	for _, segment := range segments[:steps] {
+		if isIndex(segment) {
+			index, _ := strconv.Atoi(segment)
+			if index < 0 {
+				index += len(nodes)
+			}
+			if index < 0 || index >= len(nodes) {
+				return nil, steps, nil
+			}
+			nodes = nodes[index : index+1]
+			continue
+		}
+
+		prefix, local := splitName(segment)
+		uri, bound := bindings[prefix]
+		if prefix != "" && !bound {
+			return nil, 0, newError(codeInvalidPath, "Unbound namespace prefix %q", prefix)
+		}
+		var children []*node
+		for _, n := range nodes {
+			if prefix == "" {
+				children = append(children, n.sameNameChildren(segment)...)
+				continue
+			}
+			for _, child := range n.children {
+				if child.inNamespace(uri, local, opts) {
+					children = append(children, child)
+				}
+			}
+		}
+		nodes = children
+	}
+	return nodes, steps, nil
+}
+
+// runFromElements runs the rest of a namespace-bound query from the
+// elements boundElements matched. As the library returns the first match of
+// a path, the result is that of the first element from which the rest
+// matches, in document order; a rest of just "#" counts the elements, and
+// a rest starting with a filter runs from the elements sharing a parent and
+// name. The rest is bound as bindNamespaces binds whole paths.
+func runFromElements(xml string, nodes []*node, rest []string, modifiers string, bindings map[string]string, decls []namespaceDecl, config queryConfig) map[string]any {
+	if len(rest) == 1 && rest[0] == "#" {
+		return resultToMap(xmldot.Result{Type: xmldot.Number, Num: float64(len(nodes))})
+	}
+
+	boundRest := ""
+	if len(rest) > 0 {
+		bound, matchable, err := bindNamespaces(strings.Join(rest, "."), bindings, decls)
+		if err != nil {
+			return errorResponse(err)
+		}
+		if !matchable {
+			return resultToMap(xmldot.Result{})
+		}
+		boundRest = "." + bound
+	}
+
+	tried := make(map[string]bool)
+	for _, n := range nodes {
+		path := n.canonicalPath()
+		if len(rest) > 0 && strings.HasPrefix(rest[0], "#") {
+			// Filters act on the element and its same-named siblings
+			path = strings.TrimPrefix(n.parent.canonicalPath()+"."+escapeSegment(n.name), ".")
+		}
+		if tried[path] {
+			continue
+		}
+		tried[path] = true
+
+		result := runQuery(xml, path+boundRest+modifiers, config)
+		if _, failed := result["error"]; failed || result["exists"] == true {
+			return result
+		}
+	}
+	return resultToMap(xmldot.Result{})
+}
+
+// inNamespace reports whether n has the local name local in the namespace
+// uri, resolving its prefix, or the default namespace when it has none,
+// against the namespaces in scope on n.
+func (n *node) inNamespace(uri, local string, opts *xmldot.Options) bool {
+	prefix, name := splitName(n.name)
+	if !namesMatch(name, local, opts) {
+		return false
+	}
+	bound, _ := n.inScopeNamespaces()[prefix].(string)
+	return bound == uri
+}
+
+// listNamespaces lists the namespace declarations of a document, for the
+// playground's namespace table.
+// Args: xml (string)
+// Returns: map with namespaces (prefix -> URI, the default namespace under
+// "", taking the first declaration of each prefix in document order) and
+// declarations (every declaration in document order with the canonical path
+// of the element it is written on, so redefinitions in nested scopes are
+// visible) OR error field
+func listNamespaces(this js.Value, args []js.Value) (result any) {
+	// Panic recovery with safe error return
+	defer func() {
+		if r := recover(); r != nil {
+			result = makeError(codeInternal, "Failed to list namespaces")
+		}
+	}()
+
+	// Validate argument count
+	if len(args) != 1 {
+		return makeError(codeInvalidArgument, "Expected 1 argument: xml")
+	}
+	if args[0].Type() != js.TypeString {
+		return makeError(codeInvalidArgument, "First argument (xml) must be a string")
+	}
+
+	xml := args[0].String()
+	if xmlLen := len(xml); xmlLen > xmlSizeLimit {
+		return makeError(codeTooLarge, fmt.Sprintf("XML too large (%d bytes, max %d)", xmlLen, xmlSizeLimit))
+	}
+	if err := encodingError(trimBOM(xml)); err != nil {
+		return encodingResponse(err)
+	}
+
+	root, err := parseOutline(xml)
+	if err != nil {
+		return makeError(codeMalformed, "Invalid XML document")
+	}
+
+	namespaces := map[string]any{}
+	declarations := []any{}
+	var walk func(n *node)
+	walk = func(n *node) {
+		for _, decl := range n.declaredNamespaces() {
+			if _, seen := namespaces[decl.prefix]; !seen {
+				namespaces[decl.prefix] = decl.uri
+			}
+			declarations = append(declarations, map[string]any{
+				"prefix": decl.prefix,
+				"uri":    decl.uri,
+				"path":   n.canonicalPath(),
+			})
+		}
+		for _, child := range n.children {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	return map[string]any{
+		"namespaces":   namespaces,
+		"declarations": declarations,
+	}
+}
+
+// namespaceBindings converts a JavaScript object of prefix -> URI pairs.
+func namespaceBindings(value js.Value) (map[string]string, error) {
+	if value.Type() != js.TypeObject || js.Global().Get("Array").Call("isArray", value).Bool() {
+		return nil, fmt.Errorf("Third argument (namespaces) must be an object")
+	}
+
+	keys := js.Global().Get("Object").Call("keys", value)
+	count := keys.Length()
+	if count > MaxNamespaceBindings {
+		return nil, newError(codeTooLarge, "Too many namespace bindings (%d, max %d)", count, MaxNamespaceBindings)
+	}
+
+	bindings := make(map[string]string, count)
+	for i := 0; i < count; i++ {
+		prefix := keys.Index(i).String()
+		uri := value.Get(prefix)
+		if uri.Type() != js.TypeString {
+			return nil, fmt.Errorf("Namespace URI for prefix %q must be a string", prefix)
+		}
+		bindings[prefix] = uri.String()
+	}
+	return bindings, nil
+}
+
+// bindNamespaces rewrites the prefixes used in path from the caller's
+// bindings to the prefixes the document declares for the same URIs.
+// A URI declared more than once maps to its first declaration in document
+// order; a URI declared as the default namespace maps to an unprefixed name,
+// which xmldot matches by local name. A final attribute step is rewritten
+// to "@Q{uri}local" instead, so it matches under any prefix bound to the URI
+// where the attribute is written.
+// Prefixes in the paths of "#(...)" filter conditions are rewritten too
+// (see bindCondition).
+// It reports false if a bound URI is not declared in the document, and an
+// error if path uses a prefix that has no binding.
+func bindNamespaces(path string, bindings map[string]string, decls []namespaceDecl) (string, bool, error) {
+	return bindPath(path, bindings, decls, true)
+}
+
+// bindPath is bindNamespaces, rewriting a final attribute step to
+// "@Q{uri}local" only when qualifyAttribute is set; filter conditions are
+// run by the library, which does not know that form.
+func bindPath(path string, bindings map[string]string, decls []namespaceDecl, qualifyAttribute bool) (string, bool, error) {
+	segments, modifiers := splitRawPath(path)
+
+	for i, segment := range segments {
+		if inner, ok := strings.CutPrefix(segment, "#("); ok {
+			all := strings.HasSuffix(inner, ")#")
+			inner = strings.TrimSuffix(strings.TrimSuffix(inner, "#"), ")")
+			condition, matchable, err := bindCondition(inner, bindings, decls)
+			if err != nil || !matchable {
+				return "", matchable, err
+			}
+			segments[i] = "#(" + condition + ")"
+			if all {
+				segments[i] += "#"
+			}
+			continue
+		}
+
+		isAttr := strings.HasPrefix(segment, "@")
+		name, position, positional := cutPosition(strings.TrimPrefix(segment, "@"))
+		if strings.HasPrefix(name, "#") || strings.ContainsAny(name, "()\\") {
+			continue
+		}
+
+		prefix, local, ok := strings.Cut(name, ":")
+		if !ok || prefix == "*" {
+			continue
+		}
+
+		uri, bound := bindings[prefix]
+		if !bound {
+			return "", false, newError(codeInvalidPath, "Unbound namespace prefix %q", prefix)
+		}
+
+		docPrefix, declared := declaredPrefix(uri, decls, isAttr)
+		if !declared {
+			return "", false, nil
+		}
+
+		if qualifyAttribute && isAttr && i == len(segments)-1 && !positional {
+			segments[i] = "@" + qualifiedNamePrefix + escapeSegment(uri) + "}" + escapeSegment(local)
+			continue
+		}
+
+		name = local
+		if docPrefix != "" {
+			name = docPrefix + ":" + local
+		}
+		if isAttr {
+			name = "@" + name
+		}
+		if positional {
+			name += "[" + position + "]"
+		}
+		segments[i] = name
+	}
+
+	return strings.Join(segments, ".") + modifiers, true, nil
+}
+
+// bindCondition rewrites the prefixes in a filter condition as bindPath
+// rewrites those of a path. Only the path each single condition tests is
+// rewritten, at the start of the condition and after "&&", "||" or "(";
+// values, quoted or not, are left as written.
+func bindCondition(condition string, bindings map[string]string, decls []namespaceDecl) (string, bool, error) {
+	var bound strings.Builder
+	atomStart := true
+	for i := 0; i < len(condition); i++ {
+		c := condition[i]
+		switch {
+		case atomStart && (isXMLSpace(c) || c == '('):
+			bound.WriteByte(c)
+		case atomStart:
+			atomStart = false
+			end := i
+			for end < len(condition) && strings.IndexByte(" \t\r\n=!<>%)\"'", condition[end]) < 0 {
+				if condition[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end, len(condition))
+			if end == i {
+				// Not a path: read c again as part of the condition
+				i--
+				continue
+			}
+			path, matchable, err := bindPath(condition[i:end], bindings, decls, false)
+			if err != nil || !matchable {
+				return "", matchable, err
+			}
+			bound.WriteString(path)
+			i = end - 1
+		case c == '\\' && i+1 < len(condition):
+			bound.WriteString(condition[i : i+2])
+			i++
+		case c == '"' || c == '\'':
+			end := len(condition)
+			if quote := strings.IndexByte(condition[i+1:], c); quote >= 0 {
+				end = i + quote + 2
+			}
+			bound.WriteString(condition[i:end])
+			i = end - 1
+		case strings.HasPrefix(condition[i:], conditionAnd) || strings.HasPrefix(condition[i:], conditionOr):
+			bound.WriteString(condition[i : i+2])
+			i++
+			atomStart = true
+		default:
+			bound.WriteByte(c)
+		}
+	}
+	return bound.String(), true, nil
+}
+
+// anyNamespacePrefix is the prefix wildcard of "*:interface", which selects
+// elements with that local name in any namespace or none. A plain "*" still
+// selects any element.
+const anyNamespacePrefix = "*:"
+
+// resolveAnyNamespace rewrites "*:local" element steps to "local", which
+// xmldot matches by local name whatever the element's prefix. Directly after
+// "#" the library compares names exactly, so there "*:local" only selects
+// unprefixed elements. Attributes are matched by their full name, so
+// "@*:local" is rejected.
+func resolveAnyNamespace(path string) (string, error) {
+	if !strings.Contains(path, anyNamespacePrefix) {
+		return path, nil
+	}
+
+	segments, modifiers := splitRawPath(path)
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "@"+anyNamespacePrefix) {
+			return "", newError(codeInvalidPath, "Namespace wildcards are only supported on element names")
+		}
+		if local, ok := strings.CutPrefix(segment, anyNamespacePrefix); ok {
+			segments[i] = local
+		}
+	}
+	return strings.Join(segments, ".") + modifiers, nil
+}
+
+// namespaceDeclTest is the final step selecting an element's namespace
+// declarations, which "@*" leaves out: "config.@xmlns:*".
+const namespaceDeclTest = "@xmlns:*"
+
+// isNamespaceDecl reports whether an attribute name as written by the
+// outline parser is a namespace declaration.
+func isNamespaceDecl(name xml.Name) bool {
+	return name.Space == "xmlns" || (name.Space == "" && name.Local == "xmlns")
+}
+
+// qualifiedNamePrefix starts an attribute step naming its namespace by URI,
+// like XPath's URIQualifiedName: "@Q{urn:ietf:params:xml:ns:netconf}type"
+// matches "nc:type" under whichever prefix the URI is bound to. Path syntax
+// characters in the URI, such as dots, are escaped as in any segment, and
+// "@Q{}local" only matches the unprefixed attribute. It is only supported as
+// the final step.
+const qualifiedNamePrefix = "Q{"
+
+// cutQualifiedAttribute splits an "@Q{uri}local" step into its unescaped URI
+// and local name.
+func cutQualifiedAttribute(segment string) (uri, local string, ok bool) {
+	rest, ok := strings.CutPrefix(segment, "@"+qualifiedNamePrefix)
+	if !ok {
+		return "", "", false
+	}
+	for i := 0; i < len(rest); i++ {
+		switch rest[i] {
+		case '\\':
+			i++
+		case '}':
+			uri, local = unescapePath(rest[:i]), unescapePath(rest[i+1:])
+			if local == "" || strings.ContainsAny(local, ":*") {
+				return "", "", false
+			}
+			return uri, local, true
+		}
+	}
+	return "", "", false
+}
+
+// getQualifiedAttribute evaluates "elementPath.@Q{uri}local" by resolving
+// the prefix of each attribute against the namespaces in scope on its
+// element. When elementPath ends in "#" or yields an Array, the values found
+// on each matched element are returned as an Array; otherwise the span of
+// the attribute is returned with its value.
+func getQualifiedAttribute(xml, elementPath, uri, local, modifiers string, opts *xmldot.Options) (xmldot.Result, *span, error) {
+	var elements []*node
+	parent := xmldot.GetWithOptions(xml, elementPath, opts)
+	if segments, _ := splitRawPath(elementPath); len(segments) > 1 && segments[len(segments)-1] == "#" {
+		var err error
+		if elements, err = navigationContext(xml, segments[:len(segments)-1], opts); err != nil {
+			return xmldot.Result{}, nil, err
+		}
+	} else if parent.IsArray() {
+		elements = locateMatches(xml, elementPath, parent.Results, opts)
+	}
+
+	if elements != nil {
+		result := xmldot.Result{Type: xmldot.Array}
+		for _, element := range elements {
+			if element == nil {
+				continue
+			}
+			if attr, ok := element.qualifiedAttribute(uri, local); ok {
+				result.Results = append(result.Results, xmldot.Result{Type: xmldot.Attribute, Raw: attr.Value, Str: attr.Value, Index: len(result.Results)})
+			}
+		}
+		if len(result.Results) == 0 {
+			return xmldot.Result{}, nil, nil
+		}
+		return applyModifiers(result, modifiers), nil, nil
+	}
+
+	element, ok := locateElement(xml, elementPath, parent, opts)
+	if !ok {
+		return xmldot.Result{}, nil, nil
+	}
+	attr, ok := element.qualifiedAttribute(uri, local)
+	if !ok {
+		return xmldot.Result{}, nil, nil
+	}
+	result := applyModifiers(xmldot.Result{Type: xmldot.Attribute, Raw: attr.Value, Str: attr.Value}, modifiers)
+	for _, token := range attributeTokens(xml[element.start:element.innerStart]) {
+		if token.name == qualifiedName(attr.Name) {
+			return result, &span{element.start + token.start, element.start + token.end}, nil
+		}
+	}
+	return result, nil, nil
+}
+
+// qualifiedAttribute returns the attribute of n with namespace URI uri and
+// local name local. Namespace declarations are never matched.
+func (n *node) qualifiedAttribute(uri, local string) (xml.Attr, bool) {
+	scope := n.inScopeNamespaces()
+	for _, attr := range n.attrs {
+		if attr.Name.Local != local || isNamespaceDecl(attr.Name) {
+			continue
+		}
+		if attr.Name.Space == "" && uri == "" || attr.Name.Space != "" && scope[attr.Name.Space] == uri {
+			return attr, true
+		}
+	}
+	return xml.Attr{}, false
+}
+
+// declaredPrefix returns the first prefix the document declares for uri.
+// Attributes never take the default namespace, so it is skipped for them.
+func declaredPrefix(uri string, decls []namespaceDecl, isAttr bool) (string, bool) {
+	for _, decl := range decls {
+		if decl.uri != uri || (isAttr && decl.prefix == "") {
+			continue
+		}
+		return decl.prefix, true
+	}
+	return "", false
+}
@@ -0,0 +1,137 @@
+//go:build js && wasm
+
+package main
+
+import "testing"
+
+// instances binds two prefixes to the XML Schema instance namespace.
+const instances = `<data xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" ` +
+	`xmlns:i="http://www.w3.org/2001/XMLSchema-instance" xmlns:o="urn:other">` +
+	`<a xsi:type="t1" o:type="other"/><b i:type="t2"/><c type="plain"/></data>`
+
+func TestPrefixedAttributes(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "data.a.@xsi:type", want: "t1"},
+		{path: "data.b.@i:type", want: "t2"},
+		{path: "data.a.@o:type", want: "other"},
+		{path: `data.b.@Q{http://www\.w3\.org/2001/XMLSchema-instance}type`, want: "t2"},
+		{path: "data.c.@Q{}type", want: "plain"},
+	}
+	for _, tt := range tests {
+		if value := mustQuery(t, instances, tt.path)["value"]; value != tt.want {
+			t.Errorf("%s = %q, want %q", tt.path, value, tt.want)
+		}
+	}
+	if exists := mustQuery(t, instances, "data.a.@Q{}type")["exists"]; exists != false {
+		t.Errorf("@Q{}type matched a prefixed attribute")
+	}
+}
+
+func TestBoundAttributesMatchByURI(t *testing.T) {
+	bindings := map[string]any{"x": "http://www.w3.org/2001/XMLSchema-instance"}
+	tests := []struct {
+		path   string
+		want   string
+		exists bool
+	}{
+		// Both document prefixes resolve to the bound URI
+		{path: "data.a.@x:type", want: "t1", exists: true},
+		{path: "data.b.@x:type", want: "t2", exists: true},
+		// Neither an unprefixed attribute nor one in another namespace matches
+		{path: "data.c.@x:type", want: "", exists: false},
+	}
+	for _, tt := range tests {
+		response := call(t, executeQueryWithNamespaces, instances, tt.path, bindings)
+		if response["value"] != tt.want || response["exists"] != tt.exists {
+			t.Errorf("%s = %q (exists %v), want %q", tt.path, response["value"], response["exists"], tt.want)
+		}
+	}
+}
+
+func TestAttributeWildcardExcludesDeclarations(t *testing.T) {
+	attributes := mustQuery(t, instances, "data.a.@*")["attributes"].(map[string]any)
+	if len(attributes) != 2 || attributes["xsi:type"] != "t1" || attributes["o:type"] != "other" {
+		t.Errorf("data.a.@* attributes = %v, want xsi:type and o:type", attributes)
+	}
+	if exists := mustQuery(t, instances, "data.@*")["exists"]; exists != false {
+		t.Errorf("data.@* matched namespace declarations")
+	}
+
+	results := mustQuery(t, instances, "data."+namespaceDeclTest)["results"].([]any)
+	if len(results) != 3 {
+		t.Errorf("data.%s returned %d declarations, want 3", namespaceDeclTest, len(results))
+	}
+}
+
+// partway declares a default namespace below the document element and
+// undeclares it again further down.
+const partway = `<config><system><hostname>r1</hostname></system>` +
+	`<interfaces xmlns="urn:ietf:params:xml:ns:yang:ietf-interfaces">` +
+	`<interface><name>Gi0/0</name><x xmlns=""><name>plain</name></x></interface>` +
+	`</interfaces><name>top</name></config>`
+
+func TestDefaultNamespaceBoundToPrefix(t *testing.T) {
+	bindings := map[string]any{"if": "urn:ietf:params:xml:ns:yang:ietf-interfaces"}
+	tests := []struct {
+		path string
+		want string // empty when nothing matches
+	}{
+		{path: "config.if:interfaces.if:interface.if:name", want: "Gi0/0"},
+		// Elements above the declaration are in no namespace
+		{path: "config.if:name", want: ""},
+		{path: "config.system.hostname", want: "r1"},
+		// xmlns="" takes x and its children out of the namespace again
+		{path: "config.if:interfaces.if:interface.if:x", want: ""},
+		{path: "config.if:interfaces.if:interface.x.name", want: "plain"},
+	}
+	for _, tt := range tests {
+		response := call(t, executeQueryWithNamespaces, partway, tt.path, bindings)
+		if response["value"] != tt.want || response["exists"] != (tt.want != "") {
+			t.Errorf("%s = %q (exists %v), want %q", tt.path, response["value"], response["exists"], tt.want)
+		}
+	}
+}
+
+func TestDefaultNamespaceWithoutBindings(t *testing.T) {
+	// Without namespace configuration names are matched literally
+	if value := mustQuery(t, partway, "config.interfaces.interface.name")["value"]; value != "Gi0/0" {
+		t.Errorf("got %q, want Gi0/0", value)
+	}
+	if value := mustQuery(t, partway, "config.name")["value"]; value != "top" {
+		t.Errorf("got %q, want top", value)
+	}
+}
+
+// prefixed writes its interfaces with a prefix other than the one bound.
+const prefixed = `<data xmlns:x="urn:if"><x:interfaces>` +
+	`<x:interface><x:name>e0</x:name><x:mtu>1500</x:mtu></x:interface>` +
+	`<x:interface x:type="eth"><x:name>e1</x:name><x:mtu>9000</x:mtu></x:interface>` +
+	`</x:interfaces></data>`
+
+func TestBoundPrefixesInFilters(t *testing.T) {
+	bindings := map[string]any{"if": "urn:if"}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "data.if:interfaces.if:interface.#(if:name==e1)#.if:name", want: "e1"},
+		{path: "data.if:interfaces.if:interface.#(if:name==e1).if:mtu", want: "9000"},
+		{path: "data.if:interfaces.if:interface.#(@if:type==eth).if:name", want: "e1"},
+		{path: "data.if:interfaces.if:interface.#(if:mtu>1500 && if:name!=e0).if:name", want: "e1"},
+		{path: `data.if:interfaces.if:interface.#(if:name=="if:e1").if:name`, want: ""},
+	}
+	for _, tt := range tests {
+		response := call(t, executeQueryWithNamespaces, prefixed, tt.path, bindings)
+		if response["value"] != tt.want {
+			t.Errorf("%s = %v, want %q", tt.path, response, tt.want)
+		}
+	}
+
+	path := "data.if:interfaces.if:interface.#(nc:name==e1)"
+	if response := call(t, executeQueryWithNamespaces, prefixed, path, bindings); response["code"] != codeInvalidPath {
+		t.Errorf("%s: got %v, want an invalidPath error for the unbound prefix", path, response)
+	}
+}
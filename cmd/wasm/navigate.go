@@ -0,0 +1,424 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+
+	"github.com/netascode/xmldot"
+)
+
+// parentTest is the step selecting the parent of each element matched so
+// far, like XPath's "..": "interfaces.interface.name.#(.==Gi0/0).parent()".
+const parentTest = "parent()"
+
+// selfValue is the left-hand side of a filter comparing an element's own
+// text rather than a child's: "#(.==Gi0/0)". Written as
+// "normalize-space(.)", the text is trimmed and inner whitespace collapsed
+// before comparing; otherwise it is compared as written.
+const (
+	selfValue          = "."
+	selfValueNormalize = "normalize-space(.)"
+)
+
+// isNavigationStep reports whether segment (without a positional suffix) is
+// a parent() or following-sibling() step, a position() or attribute
+// predicate, a filter on the element's own text or a filter combining
+// conditions with && or ||, which the library
+// does not support and evaluate handles on the outline instead.
+func isNavigationStep(segment string) bool {
+	if segment == parentTest || segment == followingSiblingTest || isPositionFilter(segment) || isAttributePredicate(segment) {
+		return true
+	}
+	if _, _, ok, err := cutBooleanFilter(segment); ok || err != nil {
+		return true
+	}
+	_, _, _, ok := cutSelfFilter(segment)
+	return ok
+}
+
+// hasNavigationStep reports whether any of segments is a navigation step,
+// positional or not.
+func hasNavigationStep(segments []string) bool {
+	for _, segment := range segments {
+		if base, _, _ := cutPosition(segment); isNavigationStep(base) {
+			return true
+		}
+	}
+	return false
+}
+
+// An attribute predicate keeps the matches of the step before it that carry
+// an attribute, whatever its value: "interface[@shutdown]" selects the
+// interfaces with a shutdown attribute, and "interface[not(@shutdown)]"
+// those without one. Names are compared as written, prefix included, and
+// case-insensitively when caseSensitive is false. "[@*]" tests for any
+// attribute and "[not(@*)]" for none; like "@*", they ignore namespace
+// declarations, so an element carrying only xmlns attributes has none.
+// Value tests remain filters: "interface.#(@shutdown==true)#".
+
+// isAttributePredicate reports whether segment is a "[@name]" or
+// "[not(@name)]" step.
+func isAttributePredicate(segment string) bool {
+	_, _, ok := cutAttributePredicate(segment)
+	return ok
+}
+
+// cutAttributePredicate returns the attribute name of a "[@name]" or
+// "[not(@name)]" step and whether the test is negated.
+func cutAttributePredicate(segment string) (name string, negate, ok bool) {
+	inner, ok := strings.CutPrefix(segment, "[")
+	if !ok {
+		return "", false, false
+	}
+	if inner, ok = strings.CutSuffix(inner, "]"); !ok {
+		return "", false, false
+	}
+	inner = strings.TrimSpace(inner)
+	if rest, found := strings.CutPrefix(inner, "not("); found {
+		if rest, found = strings.CutSuffix(rest, ")"); !found {
+			return "", false, false
+		}
+		inner, negate = strings.TrimSpace(rest), true
+	}
+	name, ok = strings.CutPrefix(inner, "@")
+	if name = unescapePath(name); !ok || (name != "*" && !isXMLName(name)) {
+		return "", false, false
+	}
+	return name, negate, true
+}
+
+// filterByAttribute keeps the nodes that carry the attribute name ("*" for
+// any), or with negate those that do not, in document order.
+func filterByAttribute(nodes []*node, name string, negate bool, opts *xmldot.Options) []*node {
+	var result []*node
+	for _, n := range nodes {
+		found := false
+		for _, attr := range n.attrs {
+			if !isNamespaceDecl(attr.Name) && (name == "*" || namesMatch(qualifiedName(attr.Name), name, opts)) {
+				found = true
+				break
+			}
+		}
+		if found != negate {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+// cutSelfFilter reports whether segment is a "#(.op value)" or
+// "#(.op value)#" filter and returns its condition with the element's text
+// as the child "v", whether the text is normalized first, and whether all
+// matches are kept.
+func cutSelfFilter(segment string) (condition string, normalize, all, ok bool) {
+	inner, ok := strings.CutPrefix(segment, "#(")
+	if !ok {
+		return "", false, false, false
+	}
+	if all = strings.HasSuffix(inner, ")#"); all {
+		inner = strings.TrimSuffix(inner, ")#")
+	} else if inner, ok = strings.CutSuffix(inner, ")"); !ok {
+		return "", false, false, false
+	}
+
+	inner = strings.TrimSpace(inner)
+	rest, normalize := strings.CutPrefix(inner, selfValueNormalize)
+	if !normalize {
+		if rest, ok = strings.CutPrefix(inner, selfValue); !ok {
+			return "", false, false, false
+		}
+	}
+	rest = strings.TrimSpace(rest)
+	if rest == "" || !strings.ContainsRune("=!<>%", rune(rest[0])) {
+		return "", false, false, false
+	}
+	return "v" + rest, normalize, all, true
+}
+
+// evaluateNavigation evaluates a path containing parent() or
+// following-sibling() steps, filters on an element's own text or boolean
+// filters. The
+// navigation steps act on the set of elements matched by the path before
+// them (see navigationContext); a following-sibling() step after a comment
+// or processing instruction step starts from the elements those nodes
+// precede instead (see markupSiblings). Any path after
+// them is evaluated from each remaining element, and several outcomes are
+// combined into an Array, capped at the library's MaxWildcardResults.
+func evaluateNavigation(doc string, segments []string, modifiers string, opts *xmldot.Options) (evaluation, error) {
+	first := 0
+	for first < len(segments) {
+		if base, _, _ := cutPosition(segments[first]); isNavigationStep(base) {
+			break
+		}
+		first++
+	}
+	if first == 0 {
+		return evaluation{}, newError(codeInvalidPath, "%s must follow an element path", segments[0])
+	}
+
+	var nodes []*node
+	var err error
+	fromMarkup := false
+	if prev, _, _ := cutPosition(segments[first-1]); isMarkupTest(prev) {
+		if base, _, _ := cutPosition(segments[first]); base != followingSiblingTest {
+			return evaluation{}, newError(codeInvalidPath, "Only %s may follow %s", followingSiblingTest, prev)
+		}
+		nodes, err = markupSiblings(doc, segments[:first], opts)
+		fromMarkup = true
+	} else {
+		nodes, err = navigationContext(doc, segments[:first], opts)
+	}
+	if err != nil {
+		return evaluation{}, err
+	}
+
+	next := first
+	for ; next < len(segments); next++ {
+		base, position, positional := cutPosition(segments[next])
+		if !isNavigationStep(base) {
+			break
+		}
+		switch {
+		case base == parentTest:
+			nodes = parents(nodes)
+		case base == followingSiblingTest:
+			// Already applied when the context is a set of comments or PIs
+			if !fromMarkup || next > first {
+				nodes = followingSiblings(nodes)
+			}
+		case isPositionFilter(base):
+			test, err := parsePositionTest(base)
+			if err != nil {
+				return evaluation{}, err
+			}
+			nodes = filterByPosition(nodes, test)
+		case isAttributePredicate(base):
+			name, negate, _ := cutAttributePredicate(base)
+			nodes = filterByAttribute(nodes, name, negate, opts)
+		default:
+			c, all, boolean, err := cutBooleanFilter(base)
+			if err != nil {
+				return evaluation{}, err
+			}
+			if boolean {
+				nodes = filterByCondition(doc, nodes, c, opts)
+			} else {
+				var condition string
+				var normalize bool
+				condition, normalize, all, _ = cutSelfFilter(base)
+				nodes = filterByText(doc, nodes, condition, normalize)
+			}
+			if !all && len(nodes) > 1 {
+				nodes = nodes[:1]
+			}
+		}
+		if positional {
+			index, inRange, err := parsePosition(position)
+			if err != nil {
+				return evaluation{}, err
+			}
+			if !inRange {
+				return evaluation{result: xmldot.Result{}}, nil
+			}
+			nodes = filterByPosition(nodes, indexTest(index))
+		}
+	}
+
+	rest := strings.Join(segments[next:], ".")
+	if len(nodes) == 1 && rest != "#" {
+		path := nodes[0].canonicalPath()
+		if rest != "" {
+			path += "." + rest
+		}
+		return evaluate(doc, path+modifiers, opts)
+	}
+
+	// A final "#" counts the combined matches rather than those of each element
+	count := next < len(segments) && segments[len(segments)-1] == "#"
+	if count {
+		rest = strings.Join(segments[next:len(segments)-1], ".")
+	}
+
+	var results []xmldot.Result
+	var matches []*node
+	for _, n := range nodes {
+		if rest == "" {
+			results = append(results, xmldot.Result{
+				Type: xmldot.Element,
+				Raw:  doc[n.innerStart:n.innerEnd],
+				Str:  charData(doc[n.innerStart:n.innerEnd]),
+			})
+			matches = append(matches, n)
+			continue
+		}
+
+		// The rest is evaluated against the element's own markup, which is
+		// much cheaper than walking the whole document for every element
+		if err := checkDeadline(); err != nil {
+			return evaluation{}, err
+		}
+		eval, err := evaluate(doc[n.start:n.end], escapeSegment(n.name)+"."+rest, opts)
+		if err != nil {
+			return evaluation{}, err
+		}
+		items := eval.result.Results
+		if !eval.result.IsArray() {
+			items = nil
+			if eval.result.Exists() {
+				items = []xmldot.Result{eval.result}
+			}
+		}
+		results = append(results, items...)
+
+		// Matches in the element's own markup are mapped back to the document
+		for i := range items {
+			var match *node
+			if i < len(eval.matches) && eval.matches[i] != nil {
+				match = n.find(n.start + eval.matches[i].start)
+			}
+			matches = append(matches, match)
+		}
+	}
+	if len(results) > xmldot.MaxWildcardResults {
+		// Combined matches are capped like the library's own
+		results = results[:xmldot.MaxWildcardResults]
+		matches = matches[:xmldot.MaxWildcardResults]
+	}
+	if count {
+		return evaluation{result: xmldot.Result{Type: xmldot.Number, Num: float64(len(results))}}, nil
+	}
+	if len(results) == 0 {
+		return evaluation{result: xmldot.Result{}}, nil
+	}
+	result := xmldot.Result{Type: xmldot.Array, Results: results}
+	if modifiers != "" {
+		result = applyModifiers(result, modifiers)
+		var located []*node
+		for _, n := range matches {
+			if n != nil {
+				located = append(located, n)
+			}
+		}
+		matches = pairMatches(doc, located, result.Results, false)
+	}
+	return evaluation{result: result, matches: matches}, nil
+}
+
+// navigationContext returns the elements matched by the path before the
+// first navigation step, in document order. Paths of element names and
+// indexes select every match, as XPath does, with an index picking from all
+// matches so far; other paths are evaluated by the library, and a final
+// plain name then selects the match and its same-named siblings.
+func navigationContext(doc string, segments []string, opts *xmldot.Options) ([]*node, error) {
+	path := strings.Join(segments, ".")
+	if names, ok := splitSimplePath(path); ok && !strings.Contains(path, "[") {
+		root, err := parseOutline(doc)
+		if err != nil {
+			return nil, nil
+		}
+		nodes := []*node{root}
+		for _, name := range names {
+			if index, err := strconv.Atoi(name); err == nil {
+				if index < 0 {
+					index += len(nodes)
+				}
+				if index < 0 || index >= len(nodes) {
+					return nil, nil
+				}
+				nodes = nodes[index : index+1]
+				continue
+			}
+			var children []*node
+			for _, n := range nodes {
+				children = append(children, n.childrenNamed(name, opts)...)
+			}
+			nodes = children
+		}
+		return nodes, nil
+	}
+
+	eval, err := evaluate(doc, path, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []*node
+	switch {
+	case eval.element != nil:
+		nodes = []*node{eval.element}
+		if _, _, positional := cutPosition(segments[len(segments)-1]); !positional && isPlainName(segments[len(segments)-1]) {
+			nodes = eval.element.parent.sameNameChildren(eval.element.name)
+		}
+	default:
+		nodes = eval.matches
+	}
+
+	located := nodes[:0]
+	for _, n := range nodes {
+		if n != nil {
+			located = append(located, n)
+		}
+	}
+	return located, nil
+}
+
+// parents returns the distinct parent elements of nodes in document order.
+// The document element has no parent element and contributes nothing, nor
+// does the document node, which "0" selects in a navigation context.
+func parents(nodes []*node) []*node {
+	var result []*node
+	seen := make(map[*node]bool)
+	for _, n := range nodes {
+		if parent := n.parent; parent != nil && parent.parent != nil && !seen[parent] {
+			seen[parent] = true
+			result = append(result, parent)
+		}
+	}
+	return result
+}
+
+// followingSiblings returns the next element sibling of each of nodes, in
+// document order. The last child of an element contributes nothing, and so
+// does the document node, which has no siblings.
+func followingSiblings(nodes []*node) []*node {
+	var result []*node
+	for _, n := range nodes {
+		if n.parent == nil {
+			continue
+		}
+		siblings := n.parent.children
+		for i, sibling := range siblings[:len(siblings)-1] {
+			if sibling == n {
+				result = append(result, siblings[i+1])
+				break
+			}
+		}
+	}
+	return result
+}
+
+// filterByText keeps the nodes whose text satisfies condition, a filter
+// condition on the child "v". Each text is wrapped as that child of a
+// synthetic document so the library compares it exactly as it compares
+// child values in "#(child==value)" filters.
+func filterByText(doc string, nodes []*node, condition string, normalize bool) []*node {
+	var result []*node
+	for _, n := range nodes {
+		text := descendantText(doc[n.innerStart:n.innerEnd])
+		if normalize {
+			text = normalizeSpace(text)
+		}
+
+		var wrapped strings.Builder
+		wrapped.WriteString("<r><s><v>")
+		xml.EscapeText(&wrapped, []byte(text))
+		wrapped.WriteString("</v></s></r>")
+		if xmldot.Get(wrapped.String(), "r.s.#("+condition+")").Exists() {
+			result = append(result, n)
+		}
+	}
+	return result
+}
@@ -0,0 +1,13 @@
+//go:build js && wasm
+
+package main
+
+import "testing"
+
+func TestNavigationFromDocumentNode(t *testing.T) {
+	for _, path := range []string{"0.parent()", "0.following-sibling()"} {
+		if response := mustQuery(t, devices, path); response["exists"] != false {
+			t.Errorf("%s: got %v, want no match", path, response)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"strings"
+
+	"github.com/netascode/xmldot"
+)
+
+// commentTest is the node test selecting comment children, e.g. "config.comment()".
+const commentTest = "comment()"
+
+// piTestPrefix starts the processing instruction node test, which is written
+// as "processing-instruction()" or "processing-instruction('target')".
+const piTestPrefix = "processing-instruction("
+
+// textTest is the node test selecting the text children of an element, e.g.
+// "p.text()". Unlike "p.%", which concatenates the element's direct text,
+// it returns each text node separately.
+const textTest = "text()"
+
+// precedingCommentTest is the node test selecting the comments directly
+// before an element, after its previous element sibling, e.g.
+// "config.interface.preceding-comment()". Tools that annotate elements with
+// the comments written above them read them this way.
+const precedingCommentTest = "preceding-comment()"
+
+// followingSiblingTest is the step selecting the element that a comment or
+// processing instruction precedes, its next element sibling, e.g.
+// "config.comment()[2].following-sibling().@name". Any path after it is
+// evaluated from that element.
+const followingSiblingTest = "following-sibling()"
+
+// isMarkupTest reports whether segment (without a positional suffix) is a
+// comment, preceding-comment or processing instruction node test.
+func isMarkupTest(segment string) bool {
+	return segment == commentTest || segment == precedingCommentTest || strings.HasPrefix(segment, piTestPrefix)
+}
+
+// getMarkup evaluates a final comment(), preceding-comment() or
+// processing-instruction(...) step, optionally positional, against the
+// element at parentPath (or the document when parentPath is empty). Element
+// queries never match comments or processing instructions.
+func getMarkup(xml, parentPath, segment, modifiers string, opts *xmldot.Options) (xmldot.Result, error) {
+	nodes, _, err := markupNodes(xml, parentPath, segment, opts)
+	if err != nil {
+		return xmldot.Result{}, err
+	}
+	return markupResult(xml, nodes, modifiers), nil
+}
+
+// markupNodes returns the comments or processing instructions selected by
+// segment below the element at parentPath, along with the element whose
+// children they are. Nodes are in document order and a positional suffix
+// counts in that order among the selected nodes only, as XPath's child axis
+// does: "comment()[2]" is the second comment child wherever elements fall
+// between them, and "preceding-comment()[last()]" is the comment nearest to
+// the element.
+func markupNodes(xml, parentPath, segment string, opts *xmldot.Options) ([]markup, *node, error) {
+	base, position, positional := cutPosition(segment)
+	var container *node
+	var nodes []markup
+	switch {
+	case base == commentTest:
+		var ok bool
+		if container, ok = resolveContainer(xml, parentPath, opts); !ok {
+			return nil, nil, nil
+		}
+		nodes = container.comments
+	case base == precedingCommentTest:
+		if parentPath == "" {
+			return nil, nil, newError(codeInvalidPath, "%s must follow an element path", precedingCommentTest)
+		}
+		element, ok := resolveElement(xml, parentPath, opts)
+		if !ok {
+			return nil, nil, nil
+		}
+		container = element.parent
+		sibling := 0
+		for container.children[sibling] != element {
+			sibling++
+		}
+		for _, comment := range container.comments {
+			if comment.before == sibling {
+				nodes = append(nodes, comment)
+			}
+		}
+	default:
+		target, ok := parsePITest(base)
+		if !ok {
+			return nil, nil, newError(codeInvalidPath, "Invalid processing-instruction() node test")
+		}
+		if container, ok = resolveContainer(xml, parentPath, opts); !ok {
+			return nil, nil, nil
+		}
+		// The XML declaration is never returned here
+		for _, pi := range container.procInsts {
+			if strings.EqualFold(pi.target, "xml") || (target != "" && pi.target != target) {
+				continue
+			}
+			nodes = append(nodes, pi)
+		}
+	}
+
+	if positional {
+		index, inRange, err := parsePosition(position)
+		if err != nil {
+			return nil, nil, err
+		}
+		if index < 0 {
+			index += len(nodes)
+		}
+		if !inRange || index < 0 || index >= len(nodes) {
+			return nil, container, nil
+		}
+		nodes = nodes[index : index+1]
+	}
+	return nodes, container, nil
+}
+
+// markupSiblings returns the elements that the comments or processing
+// instructions selected by segments precede, in document order. A node
+// after the last element of its parent precedes nothing, and comments
+// written together above an element yield that element once.
+func markupSiblings(xml string, segments []string, opts *xmldot.Options) ([]*node, error) {
+	parentPath := strings.Join(segments[:len(segments)-1], ".")
+	nodes, container, err := markupNodes(xml, parentPath, segments[len(segments)-1], opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var elements []*node
+	seen := make(map[*node]bool)
+	for _, n := range nodes {
+		if n.before == len(container.children) {
+			continue
+		}
+		if element := container.children[n.before]; !seen[element] {
+			seen[element] = true
+			elements = append(elements, element)
+		}
+	}
+	return elements, nil
+}
+
+// getTextNodes evaluates "parentPath.text()" against the text that is
+// directly inside the element at parentPath (see textNodes). A single text
+// node yields a String result; several yield an Array in document order;
+// none yields Null. Values are returned as written, entities expanded and
+// without trimming, so "Hello " and "!" are the text nodes of
+// "<p>Hello <b>world</b>!</p>".
+func getTextNodes(xml, parentPath, modifiers string, opts *xmldot.Options) xmldot.Result {
+	element, ok := resolveElement(xml, parentPath, opts)
+	if !ok {
+		return xmldot.Result{}
+	}
+
+	nodes := textNodes(xml[element.innerStart:element.innerEnd])
+	if len(nodes) == 0 {
+		return xmldot.Result{}
+	}
+
+	results := make([]xmldot.Result, len(nodes))
+	for i, n := range nodes {
+		results[i] = xmldot.Result{
+			Type:  xmldot.String,
+			Raw:   xml[element.innerStart+n.start : element.innerStart+n.end],
+			Str:   n.text,
+			Index: i,
+		}
+	}
+
+	if len(results) == 1 {
+		return applyModifiers(results[0], modifiers)
+	}
+	return applyModifiers(xmldot.Result{Type: xmldot.Array, Results: results}, modifiers)
+}
+
+// parsePITest reports whether segment is a processing instruction node test
+// and returns its target, which may be quoted. The target is empty when the
+// test selects every processing instruction.
+func parsePITest(segment string) (target string, ok bool) {
+	rest, ok := strings.CutPrefix(segment, piTestPrefix)
+	if !ok || !strings.HasSuffix(rest, ")") {
+		return "", false
+	}
+
+	target = strings.TrimSpace(strings.TrimSuffix(rest, ")"))
+	if len(target) >= 2 && (target[0] == '\'' || target[0] == '"') && target[len(target)-1] == target[0] {
+		target = target[1 : len(target)-1]
+	}
+	return target, true
+}
+
+// markupResult converts comments or processing instructions into a result.
+// A single node yields a String result; several yield an Array in document
+// order; none yields Null. Text is trimmed of surrounding whitespace and Raw
+// holds the full markup.
+func markupResult(xml string, nodes []markup, modifiers string) xmldot.Result {
+	if len(nodes) == 0 {
+		return xmldot.Result{}
+	}
+
+	results := make([]xmldot.Result, len(nodes))
+	for i, n := range nodes {
+		results[i] = xmldot.Result{
+			Type:  xmldot.String,
+			Raw:   xml[n.start:n.end],
+			Str:   strings.TrimSpace(n.text),
+			Index: i,
+		}
+	}
+
+	if len(results) == 1 {
+		return applyModifiers(results[0], modifiers)
+	}
+	return applyModifiers(xmldot.Result{Type: xmldot.Array, Results: results}, modifiers)
+}
@@ -0,0 +1,50 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"strings"
+
+	"github.com/netascode/xmldot"
+)
+
+// normalizeSpaceOpen starts the path-level normalize-space() function, which
+// wraps a whole path: "normalize-space(interfaces.interface.description)".
+const normalizeSpaceOpen = "normalize-space("
+
+// cutNormalizeSpace reports whether path is wrapped in normalize-space() and
+// returns the wrapped path.
+func cutNormalizeSpace(path string) (string, bool) {
+	inner, ok := strings.CutPrefix(path, normalizeSpaceOpen)
+	if !ok || !strings.HasSuffix(inner, ")") {
+		return path, false
+	}
+	inner = strings.TrimSpace(strings.TrimSuffix(inner, ")"))
+	return inner, inner != ""
+}
+
+// normalizeSpace trims leading and trailing whitespace and collapses interior
+// runs into a single space, like XPath's normalize-space(). Only XML
+// whitespace (space, tab, carriage return and line feed) is affected.
+func normalizeSpace(s string) string {
+	fields := strings.FieldsFunc(s, func(c rune) bool {
+		return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+	})
+	return strings.Join(fields, " ")
+}
+
+// normalizeResult applies normalizeSpace to the string value of a result,
+// element-wise for arrays. Raw content, numbers and booleans are unchanged.
+func normalizeResult(result xmldot.Result) xmldot.Result {
+	switch result.Type {
+	case xmldot.Array:
+		items := make([]xmldot.Result, len(result.Results))
+		for i, item := range result.Results {
+			items[i] = normalizeResult(item)
+		}
+		result.Results = items
+	case xmldot.String, xmldot.Element, xmldot.Attribute:
+		result.Str = normalizeSpace(result.Str)
+	}
+	return result
+}
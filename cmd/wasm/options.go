@@ -0,0 +1,170 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+	"time"
+
+	"github.com/netascode/xmldot"
+)
+
+// queryConfig holds the settings of the optional options argument accepted
+// by the query bindings.
+type queryConfig struct {
+	opts           *xmldot.Options
+	timeout        time.Duration
+	metrics        bool
+	normalizeSpace bool
+	asJSON         bool
+	strict         bool
+	// Character references and declared entities are expanded in values
+	expandReferences bool
+}
+
+// defaultQueryConfig returns the settings used when no options are given.
+func defaultQueryConfig() queryConfig {
+	return queryConfig{
+		opts:             xmldot.DefaultOptions(),
+		timeout:          DefaultQueryTimeout * time.Millisecond,
+		expandReferences: true,
+	}
+}
+
+// parseQueryConfig converts the optional options argument of the query
+// bindings. Undefined or null selects the defaults.
+//   - caseSensitive (boolean, default true): when false, element and
+//     attribute names match case-insensitively; namespace prefixes are
+//     always matched exactly and filter conditions still compare child
+//     names as written.
+//   - timeoutMs (number, default DefaultQueryTimeout): time budget for the
+//     call, between 1 and MaxQueryTimeout.
+//   - metrics (boolean, default false): add a metrics field to the
+//     executeQuery response (see queryMetrics).
+//   - normalizeSpace (boolean, default false): trim and collapse whitespace
+//     in result values, as the normalize-space() path function does for a
+//     single path. Raw content is never changed.
+//   - asJSON (boolean, default false): add a json field holding the located
+//     element and its descendants in the xmlToJSON convention.
+//   - strict (boolean, default false): refuse documents that are only
+//     tolerated, such as several root elements or undefined entities (see
+//     strictError), with a malformed error carrying line and column.
+//   - expandReferences (boolean, default true): when false, character
+//     references and entities declared in the internal DTD subset stay as
+//     written in result values. xmldot always decodes the five predefined
+//     entities, so the raw field is the only verbatim copy.
+func parseQueryConfig(value js.Value) (queryConfig, error) {
+	config := defaultQueryConfig()
+	if value.IsUndefined() || value.IsNull() {
+		return config, nil
+	}
+	if value.Type() != js.TypeObject {
+		return queryConfig{}, fmt.Errorf("Third argument (options) must be an object")
+	}
+
+	if caseSensitive := value.Get("caseSensitive"); !caseSensitive.IsUndefined() {
+		if caseSensitive.Type() != js.TypeBoolean {
+			return queryConfig{}, fmt.Errorf("Option caseSensitive must be a boolean")
+		}
+		config.opts.CaseSensitive = caseSensitive.Bool()
+	}
+
+	if timeout := value.Get("timeoutMs"); !timeout.IsUndefined() {
+		if timeout.Type() != js.TypeNumber {
+			return queryConfig{}, fmt.Errorf("Option timeoutMs must be a number")
+		}
+		ms := timeout.Float()
+		if !(ms >= 1 && ms <= MaxQueryTimeout) {
+			return queryConfig{}, fmt.Errorf("Option timeoutMs must be between 1 and %d", MaxQueryTimeout)
+		}
+		config.timeout = time.Duration(ms * float64(time.Millisecond))
+	}
+
+	if metrics := value.Get("metrics"); !metrics.IsUndefined() {
+		if metrics.Type() != js.TypeBoolean {
+			return queryConfig{}, fmt.Errorf("Option metrics must be a boolean")
+		}
+		config.metrics = metrics.Bool()
+	}
+
+	if normalize := value.Get("normalizeSpace"); !normalize.IsUndefined() {
+		if normalize.Type() != js.TypeBoolean {
+			return queryConfig{}, fmt.Errorf("Option normalizeSpace must be a boolean")
+		}
+		config.normalizeSpace = normalize.Bool()
+	}
+
+	if asJSON := value.Get("asJSON"); !asJSON.IsUndefined() {
+		if asJSON.Type() != js.TypeBoolean {
+			return queryConfig{}, fmt.Errorf("Option asJSON must be a boolean")
+		}
+		config.asJSON = asJSON.Bool()
+	}
+
+	if strict := value.Get("strict"); !strict.IsUndefined() {
+		if strict.Type() != js.TypeBoolean {
+			return queryConfig{}, fmt.Errorf("Option strict must be a boolean")
+		}
+		config.strict = strict.Bool()
+	}
+
+	if expand := value.Get("expandReferences"); !expand.IsUndefined() {
+		if expand.Type() != js.TypeBoolean {
+			return queryConfig{}, fmt.Errorf("Option expandReferences must be a boolean")
+		}
+		config.expandReferences = expand.Bool()
+	}
+	return config, nil
+}
+
+// queryMetrics describes the cost of a query for the playground's stats.
+// xmldot parses while it evaluates and exposes no traversal counters, so
+// the query time covers both and the document size is reported in elements
+// instead of nodes visited.
+func queryMetrics(xml string, elapsed time.Duration) map[string]any {
+	metrics := map[string]any{
+		"queryMicros": elapsed.Microseconds(),
+	}
+	if root, err := parseOutline(xml); err == nil {
+		metrics["documentElements"] = root.countElements()
+	}
+	return metrics
+}
+
+// evaluationDeadline is the time by which the running query call should
+// finish, or zero outside runWithTimeout. The WASM module serves calls one
+// at a time, so a single deadline suffices.
+var evaluationDeadline time.Time
+
+// runWithTimeout runs fn with a time budget and returns its response. fn
+// checks the budget between independent steps of the evaluation (see
+// checkDeadline) and fails with a timeout error once it has expired.
+//
+// WebAssembly is single-threaded and Go cannot preempt a running
+// evaluation, and xmldot has no cancellation hook, so a single long library
+// call is only stopped at the next check after it returns. A response that
+// completes is always returned, even past the budget: the work is done, so
+// discarding it would only turn a slow success into an error.
+func runWithTimeout(timeout time.Duration, fn func() map[string]any) (response map[string]any) {
+	defer func() {
+		evaluationDeadline = time.Time{}
+		if r := recover(); r != nil {
+			response = makeError(codeInternal, "Query execution failed due to resource limits or invalid input")
+		}
+	}()
+
+	evaluationDeadline = time.Now().Add(timeout)
+	return fn()
+}
+
+// checkDeadline returns a timeout error when the time budget of the running
+// query call has expired. Evaluations call it between independent steps,
+// such as the fields of a multipath, the paths of a batch and the elements
+// of a navigation step.
+func checkDeadline() error {
+	if !evaluationDeadline.IsZero() && time.Now().After(evaluationDeadline) {
+		return newError(codeTimeout, "Query exceeded time budget")
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunWithTimeout(t *testing.T) {
+	if err := checkDeadline(); err != nil {
+		t.Errorf("checkDeadline outside runWithTimeout = %v, want nil", err)
+	}
+
+	response := runWithTimeout(time.Millisecond, func() map[string]any {
+		time.Sleep(5 * time.Millisecond)
+		if err := checkDeadline(); err != nil {
+			return errorResponse(err)
+		}
+		return map[string]any{"value": "done"}
+	})
+	if response["code"] != codeTimeout {
+		t.Errorf("expired budget: got %v, want a timeout error", response)
+	}
+
+	// A response completed past the budget is kept
+	response = runWithTimeout(time.Millisecond, func() map[string]any {
+		time.Sleep(5 * time.Millisecond)
+		return map[string]any{"value": "done"}
+	})
+	if response["value"] != "done" {
+		t.Errorf("completed call: got %v, want its result", response)
+	}
+	if err := checkDeadline(); err != nil {
+		t.Errorf("checkDeadline after runWithTimeout = %v, want nil", err)
+	}
+}
+
+func TestQueryTimeout(t *testing.T) {
+	resetCaches()
+	// The step after parent() is evaluated per element, checking the budget
+	// each time; parsing the outline alone takes longer than 1ms
+	xml := "<r>" + strings.Repeat("<a><b/></a>", 20000) + "</r>"
+	response := call(t, executeQuery, xml, "r.a.b.parent().b", map[string]any{"timeoutMs": 1})
+	if response["code"] != codeTimeout {
+		t.Errorf("got %v, want a timeout error", response)
+	}
+	if response := call(t, executeQuery, xml, "r.a.b.parent().b", map[string]any{"timeoutMs": MaxQueryTimeout}); response["error"] != nil {
+		t.Errorf("with the largest budget: got %v, want a result", response)
+	}
+}
+
+func TestTimeoutOption(t *testing.T) {
+	for _, timeout := range []any{0, -1, MaxQueryTimeout + 1, "100", nil} {
+		options := map[string]any{"timeoutMs": timeout}
+		if response := call(t, executeQuery, "<r/>", "r", options); response["code"] != codeInvalidArgument {
+			t.Errorf("timeoutMs %v: got %v, want an invalidArgument error", timeout, response)
+		}
+	}
+}
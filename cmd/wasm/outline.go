@@ -0,0 +1,791 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/netascode/xmldot"
+)
+
+// node is an element in a parsed document outline. xmldot results only carry
+// the matched content, so the outline is used to recover element details
+// (attributes, position) that the library does not expose.
+// All offsets are byte positions into the source document.
+type node struct {
+	name       string
+	attrs      []xml.Attr
+	start      int // offset of '<' in the start tag
+	end        int // offset just past the end tag
+	innerStart int // offset just past the start tag
+	innerEnd   int // offset of '<' in the end tag
+	parent     *node
+	children   []*node
+	comments   []markup
+	procInsts  []markup
+	// Position among the siblings that a segment naming the element
+	// selects (see sameNameChildren), and their number
+	sameNameIndex int
+	sameNameCount int
+}
+
+// markup is a comment or processing instruction in the outline.
+type markup struct {
+	target string // processing instruction target; empty for comments
+	text   string
+	start  int // offset of "<!--" or "<?"
+	end    int // offset just past "-->" or "?>"
+	before int // number of element siblings preceding the node
+}
+
+// parsedOutline is the outline of the document parsed last. A query looks
+// the outline up several times, to locate matches, check prefixes and
+// resolve positions, so it is parsed once per document rather than once per
+// lookup. The document is kept so it stays comparable, and outlines are
+// never modified after parsing.
+var parsedOutline struct {
+	doc  string
+	root *node
+	err  error
+}
+
+// parseOutline builds an element outline of doc. The returned node is a
+// synthetic document node whose children are the top-level elements.
+// RawToken is used so names are reported as written, prefixes included.
+// Nesting is capped at the library's MaxNestingDepth. The outline of the
+// last document is reused (see parsedOutline).
+func parseOutline(doc string) (*node, error) {
+	if (parsedOutline.root != nil || parsedOutline.err != nil) && doc == parsedOutline.doc {
+		return parsedOutline.root, parsedOutline.err
+	}
+	root, err := buildOutline(doc)
+	parsedOutline.doc, parsedOutline.root, parsedOutline.err = doc, root, err
+	return root, err
+}
+
+// buildOutline parses the outline returned by parseOutline.
+func buildOutline(doc string) (*node, error) {
+	decoder := xml.NewDecoder(strings.NewReader(doc))
+	decoder.Strict = false
+	decoder.CharsetReader = passThroughCharset
+
+	root := &node{end: len(doc), innerEnd: len(doc)}
+	current := root
+	depth := 0
+	for {
+		offset := int(decoder.InputOffset())
+		token, err := decoder.RawToken()
+		if err != nil {
+			if current != root {
+				return nil, err
+			}
+			root.indexChildren()
+			return root, nil
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if depth++; depth > xmldot.MaxNestingDepth {
+				return nil, &xml.SyntaxError{Msg: "nesting depth exceeds limit"}
+			}
+			child := &node{
+				name:       qualifiedName(t.Name),
+				attrs:      t.Copy().Attr,
+				start:      offset,
+				innerStart: int(decoder.InputOffset()),
+				parent:     current,
+			}
+			current.children = append(current.children, child)
+			current = child
+		case xml.Comment:
+			current.comments = append(current.comments, markup{
+				text:   string(t),
+				start:  offset,
+				end:    int(decoder.InputOffset()),
+				before: len(current.children),
+			})
+		case xml.ProcInst:
+			current.procInsts = append(current.procInsts, markup{
+				target: t.Target,
+				text:   string(t.Inst),
+				start:  offset,
+				end:    int(decoder.InputOffset()),
+				before: len(current.children),
+			})
+		case xml.EndElement:
+			if current == root {
+				return nil, &xml.SyntaxError{Msg: "unexpected end element </" + qualifiedName(t.Name) + ">"}
+			}
+			current.innerEnd = offset
+			if current.innerEnd < current.innerStart {
+				// Self-closing tag: the end element is synthesized
+				current.innerEnd = current.innerStart
+			}
+			current.end = int(decoder.InputOffset())
+			current.indexChildren()
+			current = current.parent
+			depth--
+		}
+	}
+}
+
+// indexChildren sets the same-name positions of the children of n in one
+// pass, so canonical paths take linear time in large arrays.
+func (n *node) indexChildren() {
+	if len(n.children) < 2 {
+		for _, child := range n.children {
+			child.sameNameCount = 1
+		}
+		return
+	}
+
+	// A prefixed name selects that name only, an unprefixed one every
+	// element with its local name
+	exact, local := make(map[string]int), make(map[string]int)
+	for _, child := range n.children {
+		_, childLocal := splitName(child.name)
+		exact[child.name]++
+		local[childLocal]++
+	}
+	exactSeen, localSeen := make(map[string]int), make(map[string]int)
+	for _, child := range n.children {
+		prefix, childLocal := splitName(child.name)
+		if prefix != "" {
+			child.sameNameIndex, child.sameNameCount = exactSeen[child.name], exact[child.name]
+		} else {
+			child.sameNameIndex, child.sameNameCount = localSeen[childLocal], local[childLocal]
+		}
+		exactSeen[child.name]++
+		localSeen[childLocal]++
+	}
+}
+
+// namespaceDecl is an xmlns declaration found in a document.
+type namespaceDecl struct {
+	prefix string // empty for the default namespace
+	uri    string
+}
+
+// namespaceDecls returns the namespace declarations of n and its descendants
+// in document order.
+func (n *node) namespaceDecls() []namespaceDecl {
+	decls := n.declaredNamespaces()
+	for _, child := range n.children {
+		decls = append(decls, child.namespaceDecls()...)
+	}
+	return decls
+}
+
+// declaredNamespaces returns the namespace declarations written on n itself.
+func (n *node) declaredNamespaces() []namespaceDecl {
+	var decls []namespaceDecl
+	for _, attr := range n.attrs {
+		switch {
+		case attr.Name.Space == "xmlns":
+			decls = append(decls, namespaceDecl{prefix: attr.Name.Local, uri: attr.Value})
+		case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+			decls = append(decls, namespaceDecl{uri: attr.Value})
+		}
+	}
+	return decls
+}
+
+// inScopeNamespaces returns the namespace bindings in effect at n as
+// prefix -> URI, with the default namespace under "". A declaration on a
+// nearer ancestor overrides a farther one, and an empty URI (xmlns="")
+// removes the binding.
+func (n *node) inScopeNamespaces() map[string]any {
+	var chain []*node
+	for current := n; current != nil; current = current.parent {
+		chain = append(chain, current)
+	}
+
+	scope := make(map[string]any)
+	for i := len(chain) - 1; i >= 0; i-- {
+		for _, decl := range chain[i].declaredNamespaces() {
+			if decl.uri == "" {
+				delete(scope, decl.prefix)
+			} else {
+				scope[decl.prefix] = decl.uri
+			}
+		}
+	}
+	return scope
+}
+
+// countElements returns the number of elements below n.
+func (n *node) countElements() int {
+	count := len(n.children)
+	for _, child := range n.children {
+		count += child.countElements()
+	}
+	return count
+}
+
+// childrenNamed returns the direct children of n with the given name.
+// Without opts.CaseSensitive local names are compared case-insensitively;
+// namespace prefixes are always compared exactly.
+func (n *node) childrenNamed(name string, opts *xmldot.Options) []*node {
+	var matches []*node
+	for _, child := range n.children {
+		if namesMatch(child.name, name, opts) {
+			matches = append(matches, child)
+		}
+	}
+	return matches
+}
+
+// attributeMap returns the attributes of n keyed by name.
+// Namespace-prefixed attributes keep their prefix (e.g. "xsi:type").
+func (n *node) attributeMap() map[string]any {
+	attrs := make(map[string]any, len(n.attrs))
+	for _, attr := range n.attrs {
+		attrs[qualifiedName(attr.Name)] = attr.Value
+	}
+	return attrs
+}
+
+// attributeList returns the attributes of n in source order as
+// {name, value} objects, since JavaScript objects built from attributeMap do
+// not keep that order.
+func (n *node) attributeList() []any {
+	attrs := make([]any, len(n.attrs))
+	for i, attr := range n.attrs {
+		attrs[i] = map[string]any{
+			"name":  qualifiedName(attr.Name),
+			"value": attr.Value,
+		}
+	}
+	return attrs
+}
+
+// childList returns the child elements of n in document order as
+// {name, path} objects, so a result can be navigated without re-querying.
+func (n *node) childList() []any {
+	children := make([]any, len(n.children))
+	for i, child := range n.children {
+		children[i] = map[string]any{
+			"name": child.name,
+			"path": child.canonicalPath(),
+		}
+	}
+	return children
+}
+
+// locateElement finds the outline node for an Element result of path.
+// The node's content is checked against the result's Raw so a mismatch in
+// path semantics never reports details of the wrong element.
+func locateElement(doc, path string, result xmldot.Result, opts *xmldot.Options) (*node, bool) {
+	if result.Type != xmldot.Element {
+		return nil, false
+	}
+
+	element, ok := resolveElement(doc, path, opts)
+	if !ok || !sameContent(doc[element.innerStart:element.innerEnd], result.Raw) {
+		return nil, false
+	}
+	return element, true
+}
+
+// canonicalPath returns a path that selects exactly n: element names from
+// the document element down, each followed by its index among same-named
+// siblings when it has any. Names are written as in the document, so
+// namespaced elements use the document's prefixes.
+func (n *node) canonicalPath() string {
+	var segments []string
+	for current := n; current.parent != nil; current = current.parent {
+		segment := escapeSegment(current.name)
+		if current.sameNameCount > 1 {
+			segment += "." + strconv.Itoa(current.sameNameIndex)
+		}
+		segments = append([]string{segment}, segments...)
+	}
+	return strings.Join(segments, ".")
+}
+
+// sameNameChildren returns the children of n that a path segment naming
+// name selects: exact matches for prefixed names, and any element with the
+// same local name otherwise, as xmldot matches unprefixed names.
+func (n *node) sameNameChildren(name string) []*node {
+	prefix, local := splitName(name)
+	var matches []*node
+	for _, child := range n.children {
+		_, childLocal := splitName(child.name)
+		if child.name == name || (prefix == "" && childLocal == local) {
+			matches = append(matches, child)
+		}
+	}
+	return matches
+}
+
+// locateMatches finds the outline nodes of Element results the library
+// returned for path. The outline is walked with the same segments and
+// filters to find the candidate elements; results are paired with them in
+// order when the two agree, and otherwise only where a single candidate
+// has the result's content. Results that cannot be placed, or paths the
+// walk does not model, get nil rather than a guess.
+func locateMatches(doc, path string, results []xmldot.Result, opts *xmldot.Options) []*node {
+	segments, modifiers := splitRawPath(path)
+	candidates, exact, ok := pathCandidates(doc, segments, opts)
+	if !ok {
+		return make([]*node, len(results))
+	}
+	return pairMatches(doc, candidates, results, exact && modifiers == "")
+}
+
+// pairMatches assigns Element results to candidate nodes. When ordered is
+// set, the candidates are the matches in document order, and every result
+// has the content of the candidate at the same position, results are
+// paired with them one to one; results stopped at MaxWildcardResults pair
+// with the leading candidates. Otherwise a result is only placed when
+// exactly one candidate has its content.
+func pairMatches(doc string, candidates []*node, results []xmldot.Result, ordered bool) []*node {
+	located := make([]*node, len(results))
+	if ordered && len(results) <= len(candidates) {
+		aligned := true
+		for i, result := range results {
+			if result.Type != xmldot.Element || !sameContent(doc[candidates[i].innerStart:candidates[i].innerEnd], result.Raw) {
+				aligned = false
+				break
+			}
+		}
+		if aligned {
+			copy(located, candidates[:len(results)])
+			return located
+		}
+	}
+
+	byContent := make(map[string][]*node, len(candidates))
+	for _, n := range candidates {
+		content := doc[n.innerStart:n.innerEnd]
+		byContent[content] = append(byContent[content], n)
+	}
+	var normalized map[string][]*node
+	for i, result := range results {
+		if result.Type != xmldot.Element {
+			continue
+		}
+		matches, found := byContent[result.Raw]
+		if !found && strings.Contains(result.Raw, "<") {
+			// The library may have rebuilt the start tags (see sameContent)
+			if normalized == nil {
+				normalized = make(map[string][]*node, len(candidates))
+				for _, n := range candidates {
+					content := normalizedContent(doc[n.innerStart:n.innerEnd])
+					normalized[content] = append(normalized[content], n)
+				}
+			}
+			matches = normalized[normalizedContent(result.Raw)]
+		}
+		if len(matches) == 1 {
+			located[i] = matches[0]
+		}
+	}
+	return located
+}
+
+// sameContent reports whether raw, the Raw of an Element result, is the
+// source content of an element. For some queries xmldot rebuilds the start
+// tags of nested elements, writing attributes in map order, double-quoted
+// and re-escaped, so when the text differs the markup is compared with
+// attributes taken as sets.
+func sameContent(content, raw string) bool {
+	return content == raw || (strings.Contains(raw, "<") && normalizedContent(content) == normalizedContent(raw))
+}
+
+// normalizedContent describes the tokens of an XML fragment, with the
+// attributes of each start tag sorted and all values unescaped, so that
+// fragments differing only in how their tags are written compare equal.
+func normalizedContent(fragment string) string {
+	decoder := xml.NewDecoder(strings.NewReader(fragment))
+	decoder.Strict = false
+
+	var normalized strings.Builder
+	for {
+		token, err := decoder.RawToken()
+		if err != nil {
+			break
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			start.Attr = slices.Clone(start.Attr)
+			slices.SortFunc(start.Attr, func(a, b xml.Attr) int {
+				return cmp.Or(strings.Compare(a.Name.Space, b.Name.Space), strings.Compare(a.Name.Local, b.Name.Local))
+			})
+			token = start
+		}
+		fmt.Fprintf(&normalized, "%#v", token)
+	}
+	return normalized.String()
+}
+
+// pathCandidates walks the outline of doc along library path segments and
+// returns, in document order, the elements the path can select. Where the
+// library's choice is not modelled exactly (an index picking from several
+// groups, the first match of a filter) every element it could pick is
+// kept, so no match is missing, and exact is false. It reports false for
+// segments it does not model, such as attributes, globs and a final "#"
+// count.
+func pathCandidates(doc string, segments []string, opts *xmldot.Options) (nodes []*node, exact, ok bool) {
+	root, err := parseOutline(doc)
+	if err != nil {
+		return nil, false, false
+	}
+
+	nodes, exact = []*node{root}, true
+	for i, segment := range segments {
+		var next []*node
+		switch {
+		case segment == "#":
+			if i == len(segments)-1 {
+				return nil, false, false
+			}
+			continue
+		case segment == "*":
+			for _, n := range nodes {
+				next = append(next, n.children...)
+			}
+		case segment == "**":
+			for _, n := range nodes {
+				next = append(next, n.descendants()...)
+			}
+		case isIndex(segment):
+			index, _ := strconv.Atoi(segment)
+			if index < 0 {
+				return nil, false, false
+			}
+			if index < len(nodes) {
+				next = append(next, nodes[index])
+			}
+			for _, n := range nodes {
+				if n.parent == nil {
+					continue
+				}
+				if group := n.parent.sameNameChildren(n.name); index < len(group) {
+					next = append(next, group[index])
+				}
+			}
+			if next = inDocumentOrder(next); len(next) > 1 {
+				exact = false
+			}
+		case strings.HasPrefix(segment, "#("):
+			c, all, boolean, err := cutBooleanFilter(segment)
+			if err != nil {
+				return nil, false, false
+			}
+			if !boolean {
+				inner := strings.TrimPrefix(segment, "#(")
+				if all = strings.HasSuffix(inner, ")#"); all {
+					inner = strings.TrimSuffix(inner, "#")
+				}
+				inner, ok := strings.CutSuffix(inner, ")")
+				if !ok {
+					return nil, false, false
+				}
+				c = &condition{atom: inner}
+			}
+			for _, n := range nodes {
+				if n.parent != nil && c.matches(doc, n, opts) {
+					next = append(next, n)
+				}
+			}
+			if !all && len(next) > 1 {
+				exact = false
+			}
+		default:
+			names, ok := splitSimplePath(segment)
+			if !ok || len(names) != 1 {
+				return nil, false, false
+			}
+			for _, n := range nodes {
+				// Unprefixed names also select prefixed elements
+				next = append(next, n.childrenNamed(names[0], opts)...)
+				next = append(next, n.sameNameChildren(names[0])...)
+			}
+		}
+		nodes = inDocumentOrder(next)
+	}
+
+	if len(nodes) == 1 && nodes[0] == root {
+		return nil, false, false
+	}
+	return nodes, exact, true
+}
+
+// descendants returns n and all elements below it in document order.
+func (n *node) descendants() []*node {
+	nodes := []*node{n}
+	for _, child := range n.children {
+		nodes = append(nodes, child.descendants()...)
+	}
+	return nodes
+}
+
+// inDocumentOrder sorts nodes by position and drops duplicates.
+func inDocumentOrder(nodes []*node) []*node {
+	slices.SortFunc(nodes, func(a, b *node) int { return cmp.Compare(a.start, b.start) })
+	unique := nodes[:0]
+	for i, n := range nodes {
+		if i == 0 || n != nodes[i-1] {
+			unique = append(unique, n)
+		}
+	}
+	return unique
+}
+
+// find returns the element of n's subtree starting at offset start.
+func (n *node) find(start int) *node {
+	for current := n; current != nil; {
+		if current.start == start {
+			return current
+		}
+		var next *node
+		for _, child := range current.children {
+			if child.start <= start && start < child.end {
+				next = child
+				break
+			}
+		}
+		current = next
+	}
+	return nil
+}
+
+// resolveContainer resolves path like resolveElement, except that an empty
+// path selects the document node itself.
+func resolveContainer(doc, path string, opts *xmldot.Options) (*node, bool) {
+	if path != "" {
+		return resolveElement(doc, path, opts)
+	}
+	root, err := parseOutline(doc)
+	return root, err == nil
+}
+
+// resolveElement walks the outline of doc along path without consulting
+// the library. Only plain element paths with optional numeric indexes are
+// supported; paths using wildcards, filters, attributes or modifiers are
+// not resolved.
+func resolveElement(doc, path string, opts *xmldot.Options) (*node, bool) {
+	segments, ok := splitSimplePath(path)
+	if !ok {
+		return nil, false
+	}
+
+	root, err := parseOutline(doc)
+	if err != nil {
+		return nil, false
+	}
+
+	current := root
+	group := []*node{root}
+	for _, segment := range segments {
+		if index, err := strconv.Atoi(segment); err == nil {
+			if index < 0 || index >= len(group) {
+				return nil, false
+			}
+			current = group[index]
+			group = []*node{current}
+			continue
+		}
+
+		group = current.childrenNamed(segment, opts)
+		if len(group) == 0 {
+			return nil, false
+		}
+		current = group[0]
+	}
+
+	return current, current != root
+}
+
+// splitSimplePath splits a dot-separated path into segments, honouring
+// backslash escapes. It reports false for paths using query syntax that
+// locateElement does not model.
+func splitSimplePath(path string) ([]string, bool) {
+	var segments []string
+	var segment strings.Builder
+
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case c == '\\' && i+1 < len(path):
+			i++
+			segment.WriteByte(path[i])
+		case c == '.':
+			segments = append(segments, segment.String())
+			segment.Reset()
+		case strings.IndexByte("#*@()|%{}", c) >= 0:
+			return nil, false
+		default:
+			segment.WriteByte(c)
+		}
+	}
+	segments = append(segments, segment.String())
+
+	for _, s := range segments {
+		if s == "" {
+			return nil, false
+		}
+	}
+	return segments, true
+}
+
+// charData returns all character data in an XML fragment in document order,
+// entities expanded and surrounding whitespace trimmed.
+func charData(fragment string) string {
+	return strings.TrimSpace(descendantText(fragment))
+}
+
+// descendantText concatenates the character data of an XML fragment and all
+// of its descendants in document order, with entities expanded and CDATA
+// sections included. Whitespace, including whitespace-only text between
+// child elements, is kept verbatim.
+func descendantText(fragment string) string {
+	return descendantTextWith(fragment, nil)
+}
+
+// descendantTextWith is descendantText also expanding the given entities,
+// declared in the document's internal DTD subset.
+func descendantTextWith(fragment string, entities map[string]string) string {
+	decoder := xml.NewDecoder(strings.NewReader(fragment))
+	decoder.Strict = false
+	decoder.Entity = entities
+
+	var text strings.Builder
+	for {
+		token, err := decoder.RawToken()
+		if err != nil {
+			break
+		}
+		if data, ok := token.(xml.CharData); ok {
+			text.Write(data)
+		}
+	}
+	return text.String()
+}
+
+// textNodes returns the text nodes directly inside an element's content,
+// excluding the text of child elements, with offsets into content. Child
+// elements, comments and processing instructions separate text nodes; CDATA
+// sections join the text around them, as in XPath. Whitespace-only text
+// nodes are skipped.
+func textNodes(content string) []markup {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	decoder.Strict = false
+
+	var nodes []markup
+	var current *markup
+	depth := 0
+	for {
+		offset := int(decoder.InputOffset())
+		token, err := decoder.RawToken()
+		if err != nil {
+			break
+		}
+		if data, ok := token.(xml.CharData); ok && depth == 0 {
+			if current == nil {
+				current = &markup{start: offset}
+			}
+			current.text += string(data)
+			current.end = int(decoder.InputOffset())
+			continue
+		}
+
+		switch token.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+		if current != nil && strings.TrimSpace(current.text) != "" {
+			nodes = append(nodes, *current)
+		}
+		current = nil
+	}
+	if current != nil && strings.TrimSpace(current.text) != "" {
+		nodes = append(nodes, *current)
+	}
+	return nodes
+}
+
+// namesMatch compares an element name with a path segment name.
+func namesMatch(name, segment string, opts *xmldot.Options) bool {
+	if opts.CaseSensitive {
+		return name == segment
+	}
+	prefix, local := splitName(name)
+	segmentPrefix, segmentLocal := splitName(segment)
+	return prefix == segmentPrefix && strings.EqualFold(local, segmentLocal)
+}
+
+// splitName splits a qualified name into its prefix and local name.
+func splitName(name string) (prefix, local string) {
+	if prefix, local, ok := strings.Cut(name, ":"); ok {
+		return prefix, local
+	}
+	return "", name
+}
+
+// prefixesDeclared reports whether every namespace prefix written in path
+// is used, with the same case, by an element or attribute of doc. xmldot
+// folds prefixes along with local names when matching case-insensitively,
+// while XML treats prefixes as case-sensitive; paths whose prefixes only
+// match after folding are rejected here.
+func prefixesDeclared(doc, path string) bool {
+	segments, _ := splitRawPath(path)
+
+	var wanted []string
+	for _, segment := range segments {
+		if segment == namespaceDeclTest {
+			continue
+		}
+		segment = strings.TrimPrefix(segment, "@")
+		if prefix, _, ok := strings.Cut(segment, ":"); ok && prefix != "" && !strings.ContainsAny(prefix, "#(){\\") {
+			wanted = append(wanted, prefix)
+		}
+	}
+	if len(wanted) == 0 {
+		return true
+	}
+
+	root, err := parseOutline(doc)
+	if err != nil {
+		return true
+	}
+	used := root.prefixes(map[string]bool{})
+	for _, prefix := range wanted {
+		if !used[prefix] {
+			return false
+		}
+	}
+	return true
+}
+
+// prefixes adds the namespace prefixes used by the names of n and its
+// descendants, and by their attributes, to set.
+func (n *node) prefixes(set map[string]bool) map[string]bool {
+	if prefix, _, ok := strings.Cut(n.name, ":"); ok {
+		set[prefix] = true
+	}
+	for _, attr := range n.attrs {
+		if attr.Name.Space != "" && attr.Name.Space != "xmlns" {
+			set[attr.Name.Space] = true
+		}
+	}
+	for _, child := range n.children {
+		child.prefixes(set)
+	}
+	return set
+}
+
+// qualifiedName formats a name with its namespace prefix, if any.
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
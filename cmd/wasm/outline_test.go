@@ -0,0 +1,67 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/netascode/xmldot"
+)
+
+func TestResultPaths(t *testing.T) {
+	const siblings = `<r><k>1</k><i><name>1</name></i><i><name>2</name></i></r>`
+	tests := []struct {
+		name  string
+		xml   string
+		path  string
+		paths []any // Result path of each item; "" where it is not reported
+	}{
+		{"same content elsewhere", siblings, "r.i.#.name", []any{"r.i.0.name", "r.i.1.name"}},
+		{"reversed", siblings, "r.i.#.name|@reverse", []any{"r.i.1.name", "r.i.0.name"}},
+		{"sorted descending", siblings, "r.i.#.name|@sort:desc", []any{"r.i.1.name", "r.i.0.name"}},
+		{"same content siblings", `<r><i><v>x</v></i><i><v>x</v></i></r>`, "r.i.#.v|@reverse", []any{"", ""}},
+		{"filter", `<r><i><v>1</v></i><i><v>2</v></i><i><v>1</v></i></r>`, "r.i.#(v==1)#", []any{"r.i.0", "r.i.2"}},
+		{"navigation", siblings, "r.i.#.name.parent()|@reverse", []any{"r.i.1", "r.i.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []any
+			for _, item := range mustQuery(t, tt.xml, tt.path)["results"].([]any) {
+				path, _ := item.(map[string]any)["path"].(string)
+				paths = append(paths, path)
+			}
+			if !reflect.DeepEqual(paths, tt.paths) {
+				t.Errorf("paths = %v, want %v", paths, tt.paths)
+			}
+		})
+	}
+}
+
+func TestFilterResultPath(t *testing.T) {
+	response := mustQuery(t, `<r><k><v>1</v></k><i><v>1</v></i></r>`, "r.i.#(v==1)")
+	if response["path"] != "r.i" {
+		t.Errorf("path = %v, want r.i", response["path"])
+	}
+}
+
+func TestResultPathsAtMatchLimit(t *testing.T) {
+	xml := "<r>" + strings.Repeat("<u><n>1</n></u>", xmldot.MaxWildcardResults+1) + "</r>"
+	results := mustQuery(t, xml, "**.n")["results"].([]any)
+	last := len(results) - 1
+	if path := results[last].(map[string]any)["path"]; path != fmt.Sprintf("r.u.%d.n", last) {
+		t.Errorf("path of result %d = %v", last, path)
+	}
+}
+
+func TestSameContentIgnoresRebuiltTags(t *testing.T) {
+	content := `<a xsi:type='t1' o:type="a&amp;b"/><b>x</b>`
+	if !sameContent(content, `<a o:type="a&amp;b" xsi:type="t1"/><b>x</b>`) {
+		t.Error("attributes in another order and quoting did not match")
+	}
+	if sameContent(content, `<a o:type="other" xsi:type="t1"/><b>x</b>`) {
+		t.Error("a different attribute value matched")
+	}
+}
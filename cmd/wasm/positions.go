@@ -0,0 +1,344 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/netascode/xmldot"
+)
+
+// lastPosition is the XPath function selecting the last match in a
+// positional step, optionally offset as "last()-1".
+const lastPosition = "last()"
+
+// cutPosition splits an XPath-style positional step such as "interface[2]"
+// or "interface[last()]" into its segment and the bracketed position.
+func cutPosition(segment string) (base, position string, ok bool) {
+	if !strings.HasSuffix(segment, "]") || strings.HasSuffix(segment, "\\]") {
+		return segment, "", false
+	}
+	open := strings.LastIndexByte(segment, '[')
+	if open <= 0 || segment[open-1] == '\\' {
+		return segment, "", false
+	}
+	return segment[:open], segment[open+1 : len(segment)-1], true
+}
+
+// parsePosition converts a bracketed position into a library index: n-1 for
+// the 1-based position n, and -1-k for "last()-k". Position 0 selects
+// nothing, so it reports an in-range flag of false rather than an error.
+func parsePosition(position string) (index int, inRange bool, err error) {
+	position = strings.TrimSpace(position)
+	if rest, ok := strings.CutPrefix(position, lastPosition); ok {
+		rest = strings.TrimSpace(rest)
+		if rest == "" {
+			return -1, true, nil
+		}
+		offset, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(rest, "-")))
+		if !strings.HasPrefix(rest, "-") || err != nil || offset < 0 {
+			return 0, false, newError(codeInvalidPath, "Invalid position [%s]", position)
+		}
+		return -1 - offset, true, nil
+	}
+
+	n, err := strconv.Atoi(position)
+	if err != nil && (strings.HasPrefix(position, "@") || strings.HasPrefix(position, "not(")) {
+		return 0, false, newError(codeInvalidPath, "Invalid attribute predicate [%s]; [@name] and [not(@name)] test presence, compare values with a filter such as #(@name==value)#", position)
+	}
+	if err != nil || n < 0 {
+		return 0, false, newError(codeInvalidPath, "Invalid position [%s]", position)
+	}
+	return n - 1, n > 0, nil
+}
+
+// resolvePositions rewrites the 1-based positional steps left by
+// splitPredicateSteps, those on the first segment, into paths the library
+// understands. On an element name, "interface[2]" becomes "interface.1" and
+// "interface[last()]" becomes "interface.-1", which resolveNegativeIndexes
+// then makes absolute. On any other step (wildcards, filters) the step is
+// evaluated, the selected match is located in the outline and the path up
+// to it is replaced by the match's canonical path. It reports false when a
+// position is out of range, which yields Null.
+func resolvePositions(xml, path string, opts *xmldot.Options) (string, bool, error) {
+	segments, modifiers := splitRawPath(path)
+	for i := 0; i < len(segments); i++ {
+		base, position, ok := cutPosition(segments[i])
+		if !ok || isMarkupTest(base) {
+			// Node tests select their own positions (see markupNodes)
+			continue
+		}
+		index, inRange, err := parsePosition(position)
+		if err != nil {
+			return "", false, err
+		}
+		if !inRange {
+			return "", false, nil
+		}
+
+		if isPlainName(base) {
+			segments[i] = base + "." + strconv.Itoa(index)
+			continue
+		}
+
+		prefix := strings.Join(append(append([]string{}, segments[:i]...), base), ".")
+		matches := xmldot.GetWithOptions(xml, prefix, opts)
+		items := matches.Results
+		if !matches.IsArray() {
+			items = []xmldot.Result{matches}
+			if !matches.Exists() {
+				items = nil
+			}
+		}
+		if index < 0 {
+			index += len(items)
+		}
+		if index < 0 || index >= len(items) {
+			return "", false, nil
+		}
+
+		element := locateMatches(xml, prefix, items, opts)[index]
+		if element == nil {
+			return "", false, nil
+		}
+		located, _ := splitRawPath(element.canonicalPath())
+		segments = append(located, segments[i+1:]...)
+		i = len(located) - 1
+	}
+	return strings.Join(segments, ".") + modifiers, true, nil
+}
+
+// isPlainName reports whether segment names an element, as opposed to a
+// wildcard, filter, count, index or node test.
+func isPlainName(segment string) bool {
+	if segment == "" || isIndex(segment) || strings.HasPrefix(segment, "@") {
+		return false
+	}
+	return !strings.ContainsAny(strings.NewReplacer("\\*", "", "\\?", "", "\\#", "").Replace(segment), "*?#()%")
+}
+
+// positionFunction starts a positional step whose predicate tests each
+// match's position rather than selecting one: "entry[position()<=5]" keeps
+// the first five, and "entry[position() mod 2 = 1]" the odd ones. As in
+// XPath, positions count from 1 among the matches sharing a parent, so
+// "**.entry[position()=1]" keeps the first entry of every parent.
+const positionFunction = "position()"
+
+// positionTest is a parsed position() predicate: the position, reduced
+// modulo mod when mod is not zero, compared with op against value, or
+// against the size of the sibling set less value when fromLast is set.
+type positionTest struct {
+	mod      int
+	op       string
+	value    int
+	fromLast bool
+}
+
+// isPositionFilter reports whether segment is a position() predicate split
+// from its step by splitPredicateSteps.
+func isPositionFilter(segment string) bool {
+	return strings.HasPrefix(segment, "["+positionFunction)
+}
+
+// splitPredicateSteps moves position() and attribute predicates into steps of
+// their own, "entry[position()<=5]" becoming "entry" and "[position()<=5]",
+// so they filter the matches of the step before them like other navigation
+// steps. Several predicates on a step are applied in turn, and a final
+// positional predicate stays with the last of them, so "entry[@id][2]"
+// becomes "entry" and "[@id][2]", the second entry with an id attribute.
+// A position on any other step past the first becomes a position() test,
+// "interface[2]" becoming "interface" and "[position()=2]", so positions
+// count among the matches of each parent as in XPath: "**.interface[2]"
+// selects the second interface of every element that has two.
+func splitPredicateSteps(segments []string) []string {
+	var split []string
+	for _, segment := range segments {
+		if base, p, ok := cutPosition(segment); ok && len(split) > 0 && isPositionStep(base, p) {
+			split = append(split, base, "["+positionFunction+"="+strings.TrimSpace(p)+"]")
+			continue
+		}
+
+		rest, position := segment, ""
+		if base, p, ok := cutPosition(segment); ok && !isPredicate(p) {
+			if _, inner, ok := cutPosition(base); ok && isPredicate(inner) {
+				rest, position = base, "["+p+"]"
+			}
+		}
+
+		var predicates []string
+		for {
+			base, p, ok := cutPosition(rest)
+			if !ok || !isPredicate(p) {
+				break
+			}
+			predicates = append([]string{"[" + strings.TrimSpace(p) + "]"}, predicates...)
+			rest = base
+		}
+		if len(predicates) == 0 {
+			split = append(split, segment)
+			continue
+		}
+		predicates[len(predicates)-1] += position
+		split = append(append(split, rest), predicates...)
+	}
+	return split
+}
+
+// checkPredicates parses the bracketed positions and predicates of a path
+// segment as evaluation reads them, so explainQuery rejects exactly the
+// brackets a query would.
+func checkPredicates(segment string) error {
+	for _, step := range splitPredicateSteps([]string{segment}) {
+		for step != "" {
+			base, position, ok := cutPosition(step)
+			if !ok {
+				// Predicates split into steps of their own have no base
+				inner, found := strings.CutPrefix(step, "[")
+				if position, ok = strings.CutSuffix(inner, "]"); !found || !ok {
+					break
+				}
+				base = ""
+			}
+			switch {
+			case strings.HasPrefix(strings.TrimSpace(position), positionFunction):
+				if _, err := parsePositionTest(position); err != nil {
+					return err
+				}
+			case isAttributePredicate("[" + position + "]"):
+			default:
+				if _, _, err := parsePosition(position); err != nil {
+					return err
+				}
+			}
+			step = base
+		}
+	}
+	return nil
+}
+
+// isPositionStep reports whether base[position] selects by position among
+// the matches of an element, wildcard or filter step. Node tests select
+// their own positions (see markupNodes), navigation steps apply theirs in
+// evaluateNavigation, and invalid positions are left for resolvePositions
+// to report.
+func isPositionStep(base, position string) bool {
+	if base == "" || isIndex(base) || strings.HasPrefix(base, "@") || isMarkupTest(base) || isNavigationStep(base) || isPredicate(position) {
+		return false
+	}
+	_, _, err := parsePosition(position)
+	return err == nil
+}
+
+// isPredicate reports whether the bracketed part of a step is a position()
+// or attribute predicate rather than a position.
+func isPredicate(predicate string) bool {
+	predicate = strings.TrimSpace(predicate)
+	return strings.HasPrefix(predicate, positionFunction) || isAttributePredicate("["+predicate+"]")
+}
+
+// parsePositionTest reads a position() predicate, with or without its
+// brackets: position(), optionally "mod n", then one of =, ==, !=, <, <=, >
+// and >= and an integer, last() or last()-k.
+func parsePositionTest(predicate string) (positionTest, error) {
+	invalid := newError(codeInvalidPath, "Invalid position() predicate [%s]; expected e.g. position()<=5 or position() mod 2 = 1", strings.Trim(predicate, "[]"))
+
+	rest, ok := strings.CutPrefix(strings.TrimSpace(strings.Trim(predicate, "[]")), positionFunction)
+	if !ok {
+		return positionTest{}, invalid
+	}
+	rest = strings.TrimSpace(rest)
+
+	var test positionTest
+	if after, ok := strings.CutPrefix(rest, "mod "); ok {
+		after = strings.TrimSpace(after)
+		digits := len(after) - len(strings.TrimLeft(after, "0123456789"))
+		mod, err := strconv.Atoi(after[:digits])
+		if err != nil || mod == 0 {
+			return positionTest{}, invalid
+		}
+		test.mod, rest = mod, strings.TrimSpace(after[digits:])
+	}
+
+	for _, op := range []string{"==", "!=", "<=", ">=", "=", "<", ">"} {
+		if after, ok := strings.CutPrefix(rest, op); ok {
+			test.op, rest = op, strings.TrimSpace(after)
+			break
+		}
+	}
+	if test.op == "" {
+		return positionTest{}, invalid
+	}
+	if test.op == "==" {
+		test.op = "="
+	}
+
+	if after, ok := strings.CutPrefix(rest, lastPosition); ok {
+		test.fromLast, rest = true, strings.TrimSpace(after)
+		if rest == "" {
+			return test, nil
+		}
+		if rest, ok = strings.CutPrefix(rest, "-"); !ok {
+			return positionTest{}, invalid
+		}
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(rest))
+	if err != nil || value < 0 {
+		return positionTest{}, invalid
+	}
+	test.value = value
+	return test, nil
+}
+
+// matches reports whether the 1-based position among size siblings passes.
+func (t positionTest) matches(position, size int) bool {
+	value := t.value
+	if t.fromLast {
+		value = size - t.value
+	}
+	if t.mod != 0 {
+		position %= t.mod
+	}
+	switch t.op {
+	case "=":
+		return position == value
+	case "!=":
+		return position != value
+	case "<":
+		return position < value
+	case "<=":
+		return position <= value
+	case ">":
+		return position > value
+	default:
+		return position >= value
+	}
+}
+
+// indexTest returns the position() test selecting the library index
+// returned by parsePosition: n-1 for position n, -1-k for last()-k.
+func indexTest(index int) positionTest {
+	if index < 0 {
+		return positionTest{op: "=", value: -1 - index, fromLast: true}
+	}
+	return positionTest{op: "=", value: index + 1}
+}
+
+// filterByPosition keeps the nodes whose position passes t, counting
+// positions separately among the nodes of each parent.
+func filterByPosition(nodes []*node, t positionTest) []*node {
+	sizes := make(map[*node]int)
+	for _, n := range nodes {
+		sizes[n.parent]++
+	}
+
+	var result []*node
+	positions := make(map[*node]int)
+	for _, n := range nodes {
+		positions[n.parent]++
+		if t.matches(positions[n.parent], sizes[n.parent]) {
+			result = append(result, n)
+		}
+	}
+	return result
+}
@@ -0,0 +1,61 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// devices has two parents with two and three same-named children.
+const devices = `<r><d><interface><name>a</name></interface><interface><name>b</name></interface></d>` +
+	`<d><interface><name>c</name></interface><interface><name>d</name></interface><interface><name>e</name></interface></d></r>`
+
+func TestPositionsCountPerParent(t *testing.T) {
+	tests := []struct {
+		path  string
+		value string
+		typ   string
+		at    string // Result path of a single match
+	}{
+		{path: "r.d.interface[2].name", value: `["b","d"]`, typ: "Array"},
+		{path: "**.interface[2].name", value: `["b","d"]`, typ: "Array"},
+		{path: "r.d.interface[last()].name", value: `["b","e"]`, typ: "Array"},
+		{path: "r.d.interface[last()-1].name", value: `["a","d"]`, typ: "Array"},
+		{path: "r.d.interface[3].name", value: "e", typ: "Element", at: "r.d.1.interface.2.name"},
+		{path: "r.d[2].interface[1].name", value: "c", typ: "Element", at: "r.d.1.interface.0.name"},
+		{path: "r.d[last()].interface[last()].name", value: "e", typ: "Element", at: "r.d.1.interface.2.name"},
+		{path: "r.d.interface[4]", value: "", typ: "Null"},
+		{path: "r.d.interface[0]", value: "", typ: "Null"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			response := mustQuery(t, devices, tt.path)
+			if response["value"] != tt.value || response["type"] != tt.typ {
+				t.Errorf("got %v %q, want %s %q", response["type"], response["value"], tt.typ, tt.value)
+			}
+			if tt.at != "" && response["path"] != tt.at {
+				t.Errorf("path = %v, want %s", response["path"], tt.at)
+			}
+		})
+	}
+}
+
+func TestPositionCountsMatches(t *testing.T) {
+	if count := mustQuery(t, devices, "r.d.interface[2].#")["value"]; fmt.Sprint(count) != "2" {
+		t.Errorf("count = %v, want 2", count)
+	}
+}
+
+func TestXPathPositionsCountPerParent(t *testing.T) {
+	path := call(t, xpathToQuery, "//interface[2]/name")["path"].(string)
+	if value := mustQuery(t, devices, path)["value"]; value != `["b","d"]` {
+		t.Errorf("%s = %v, want the second interface of each d", path, value)
+	}
+}
+
+func TestInvalidPosition(t *testing.T) {
+	if response := call(t, executeQuery, devices, "r.d.interface[x]"); response["code"] != codeInvalidPath {
+		t.Errorf("got %v, want an invalidPath error", response)
+	}
+}
@@ -0,0 +1,356 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/netascode/xmldot"
+)
+
+// evaluation is the outcome of a single path query.
+type evaluation struct {
+	result  xmldot.Result
+	element *node   // matched element in the outline, when it could be located
+	matches []*node // outline nodes of Element results, one per result or Array item; nil where unknown
+	cdata   bool    // result content was read from CDATA in the source
+	span    *span   // source range of the matched element or attribute, when known
+}
+
+// span is a [start, end) byte range in the source document.
+type span struct {
+	start, end int
+}
+
+// evaluate runs a single path against xml. The library handles the query
+// itself; normalize-space(), "*:local" namespace wildcards, parent(),
+// own-text and &&/|| filters, positional steps, attribute predicates,
+// negative indexes, the @* and @xmlns:* wildcards, "@Q{uri}local"
+// attributes, node tests and following-sibling() steps are handled here.
+func evaluate(xml, path string, opts *xmldot.Options) (evaluation, error) {
+	if inner, ok := cutNormalizeSpace(path); ok {
+		eval, err := evaluate(xml, inner, opts)
+		eval.result = normalizeResult(eval.result)
+		return eval, err
+	}
+
+	path, err := resolveAnyNamespace(path)
+	if err != nil {
+		return evaluation{}, err
+	}
+
+	segments, modifiers := splitRawPath(path)
+	if segments = splitPredicateSteps(segments); hasNavigationStep(segments) {
+		return evaluateNavigation(xml, segments, modifiers, opts)
+	}
+
+	if !opts.CaseSensitive && !prefixesDeclared(xml, path) {
+		return evaluation{result: xmldot.Result{}}, nil
+	}
+
+	path, ok, err := resolvePositions(xml, path, opts)
+	if err != nil || !ok {
+		return evaluation{result: xmldot.Result{}}, err
+	}
+
+	// Negative indexes are resolved here as the library only supports them in Set
+	path = resolveNegativeIndexes(xml, path, opts)
+
+	var eval evaluation
+	parentPath, last, modifiers := cutLastSegment(path)
+	lastBase, _, _ := cutPosition(last)
+	switch {
+	case (last == "@*" || last == namespaceDeclTest) && parentPath != "":
+		eval.result, eval.element = getAllAttributes(xml, parentPath, last == namespaceDeclTest, modifiers, opts)
+	case strings.HasPrefix(last, "@"+qualifiedNamePrefix) && parentPath != "":
+		uri, local, ok := cutQualifiedAttribute(last)
+		if !ok {
+			return evaluation{}, newError(codeInvalidPath, "Invalid attribute name %s", last)
+		}
+		if eval.result, eval.span, err = getQualifiedAttribute(xml, parentPath, uri, local, modifiers, opts); err != nil {
+			return evaluation{}, err
+		}
+	case isMarkupTest(lastBase):
+		if eval.result, err = getMarkup(xml, parentPath, last, modifiers, opts); err != nil {
+			return evaluation{}, err
+		}
+	case last == textTest && parentPath != "":
+		eval.result = getTextNodes(xml, parentPath, modifiers, opts)
+	case strings.HasPrefix(last, "@") && parentPath != "" && modifiers == "":
+		eval.result, _, eval.cdata = getElement(xml, path, opts)
+		if eval.result.Type == xmldot.Attribute {
+			eval.span = attributeSpan(xml, parentPath, last[1:], opts)
+		}
+	case opts.CaseSensitive || modifiers == "":
+		eval.result, eval.element, eval.cdata = getElement(xml, path, opts)
+	default:
+		// xmldot's options-aware query path does not apply modifiers
+		eval.result, _, eval.cdata = getElement(xml, strings.TrimSuffix(path, modifiers), opts)
+		eval.result = applyModifiers(eval.result, modifiers)
+	}
+	switch {
+	case eval.element != nil:
+		eval.matches = []*node{eval.element}
+	case eval.result.IsArray():
+		eval.matches = locateMatches(xml, path, eval.result.Results, opts)
+	case eval.result.Type == xmldot.Element:
+		eval.matches = locateMatches(xml, path, []xmldot.Result{eval.result}, opts)
+	}
+	if eval.element != nil && eval.span == nil {
+		eval.span = &span{eval.element.start, eval.element.end}
+	}
+	return eval, nil
+}
+
+// attributeSpan returns the source range of the name="value" token of an
+// attribute of the element at elementPath. Names are matched as written,
+// falling back to the local name as xmldot does for unprefixed paths.
+func attributeSpan(xml, elementPath, name string, opts *xmldot.Options) *span {
+	element, ok := locateElement(xml, elementPath, xmldot.GetWithOptions(xml, elementPath, opts), opts)
+	if !ok {
+		return nil
+	}
+	return element.attributeSpan(xml, name, opts)
+}
+
+// attributeSpan returns the source range of the name="value" token of the
+// attribute of n that a path step "@name" selects.
+func (n *node) attributeSpan(doc, name string, opts *xmldot.Options) *span {
+	tokens := attributeTokens(doc[n.start:n.innerStart])
+	for _, exact := range []bool{true, false} {
+		for _, token := range tokens {
+			tokenName := token.name
+			if !exact {
+				_, tokenName = splitName(tokenName)
+			}
+			if namesMatch(tokenName, name, opts) {
+				return &span{n.start + token.start, n.start + token.end}
+			}
+		}
+	}
+	return nil
+}
+
+// attributeToken is an attribute in a start tag as written.
+type attributeToken struct {
+	name       string
+	start, end int // offsets into the start tag
+}
+
+// attributeTokens scans a well-formed start tag for its attributes.
+func attributeTokens(tag string) []attributeToken {
+	var tokens []attributeToken
+	isSpace := func(c byte) bool { return c == ' ' || c == '\t' || c == '\n' || c == '\r' }
+
+	// Skip "<" and the element name
+	i := 1
+	for i < len(tag) && !isSpace(tag[i]) && tag[i] != '>' && tag[i] != '/' {
+		i++
+	}
+	for i < len(tag) {
+		for i < len(tag) && isSpace(tag[i]) {
+			i++
+		}
+		if i >= len(tag) || tag[i] == '>' || tag[i] == '/' {
+			break
+		}
+
+		start := i
+		for i < len(tag) && tag[i] != '=' && !isSpace(tag[i]) {
+			i++
+		}
+		name := tag[start:i]
+		for i < len(tag) && (isSpace(tag[i]) || tag[i] == '=') {
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		quote := tag[i]
+		end := strings.IndexByte(tag[i+1:], quote)
+		if end < 0 {
+			break
+		}
+		i += end + 2
+		tokens = append(tokens, attributeToken{name: name, start: start, end: i})
+	}
+	return tokens
+}
+
+// resolveNegativeIndexes rewrites negative index segments (-1 for the last
+// match, -2 for the one before it, ...) into absolute indexes. xmldot only
+// honours negative indexes in Set, so Get needs the match count first.
+// The count comes from the same sibling set a positive index selects from,
+// so "logs.entry.-1" is the last entry of the first logs element.
+// Out-of-range indexes are left untouched and yield a Null result.
+func resolveNegativeIndexes(xml, path string, opts *xmldot.Options) string {
+	segments, modifiers := splitRawPath(path)
+
+	changed := false
+	for i := 1; i < len(segments); i++ {
+		index, err := strconv.Atoi(segments[i])
+		if err != nil || index >= 0 {
+			continue
+		}
+
+		count := xmldot.GetWithOptions(xml, strings.Join(segments[:i], ".")+".#", opts).Int()
+		if count <= 0 || int64(-index) > count {
+			continue
+		}
+		segments[i] = strconv.FormatInt(count+int64(index), 10)
+		changed = true
+	}
+
+	if !changed {
+		return path
+	}
+	return strings.Join(segments, ".") + modifiers
+}
+
+// isTruncated reports whether result was cut short by the library's
+// MaxWildcardResults limit: an Array holding that many matches, or a "#"
+// count equal to it, since counts are capped at the same limit.
+func isTruncated(result xmldot.Result, path string) bool {
+	if result.IsArray() {
+		return len(result.Results) >= xmldot.MaxWildcardResults
+	}
+	_, last, modifiers := cutLastSegment(path)
+	return last == "#" && modifiers == "" && result.Int() >= xmldot.MaxWildcardResults
+}
+
+// splitRawPath splits path into dot-separated segments, leaving escape
+// sequences and filter expressions intact, and returns any "|@modifier"
+// suffix separately.
+func splitRawPath(path string) (segments []string, modifiers string) {
+	depth := 0
+	start := 0
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case '.':
+			if depth == 0 {
+				segments = append(segments, path[start:i])
+				start = i + 1
+			}
+		case '|':
+			if depth == 0 {
+				return append(segments, path[start:i]), path[i:]
+			}
+		}
+	}
+	return append(segments, path[start:]), ""
+}
+
+// cutLastSegment splits path into its parent path, final segment and any
+// modifier suffix. The parent is empty for single-segment paths.
+func cutLastSegment(path string) (parent, last, modifiers string) {
+	segments, modifiers := splitRawPath(path)
+	lastIndex := len(segments) - 1
+	return strings.Join(segments[:lastIndex], "."), segments[lastIndex], modifiers
+}
+
+// getAllAttributes evaluates "elementPath.@*": an Array of the element's
+// attribute values in source order, prefixed attributes included. Namespace
+// declarations are only returned, by themselves, for "elementPath.@xmlns:*"
+// (namespaceDecls). xmldot has no attribute wildcard, so the element is
+// located in the outline, which limits elementPath to plain element paths.
+// Elements without such attributes yield Null.
+func getAllAttributes(xml, elementPath string, namespaceDecls bool, modifiers string, opts *xmldot.Options) (xmldot.Result, *node) {
+	element, ok := locateElement(xml, elementPath, xmldot.GetWithOptions(xml, elementPath, opts), opts)
+	if !ok {
+		return xmldot.Result{}, nil
+	}
+
+	result := xmldot.Result{Type: xmldot.Array}
+	for _, attr := range element.attrs {
+		if isNamespaceDecl(attr.Name) != namespaceDecls {
+			continue
+		}
+		result.Results = append(result.Results, xmldot.Result{
+			Type:  xmldot.Attribute,
+			Raw:   attr.Value,
+			Str:   attr.Value,
+			Index: len(result.Results),
+		})
+	}
+	if len(result.Results) == 0 {
+		return xmldot.Result{}, nil
+	}
+	return applyModifiers(result, modifiers), element
+}
+
+// applyModifiers applies a "|@name|@name" suffix to a result built outside
+// the library, following xmldot's rules: unknown modifiers and chains longer
+// than MaxModifierChainDepth yield Null.
+func applyModifiers(result xmldot.Result, modifiers string) xmldot.Result {
+	if modifiers == "" {
+		return result
+	}
+
+	names := strings.Split(modifiers, "|")[1:]
+	if len(names) > xmldot.MaxModifierChainDepth {
+		return xmldot.Result{}
+	}
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if !strings.HasPrefix(name, "@") {
+			continue
+		}
+		modifier := xmldot.GetModifier(name[1:])
+		if modifier == nil {
+			return xmldot.Result{}
+		}
+		if result = modifier.Apply(result); result.Type == xmldot.Null {
+			break
+		}
+	}
+	return result
+}
+
+// getElement evaluates a regular path and locates the matched element in the
+// outline when possible. xmldot mis-scans markup characters inside CDATA
+// sections, so for elements whose content contains CDATA the value and raw
+// content are read from the source instead; cdata reports when that happened.
+// References in library results are expanded later (see expandReferences),
+// but content read from the source is decoded here.
+func getElement(xml, path string, opts *xmldot.Options) (result xmldot.Result, element *node, cdata bool) {
+	result = xmldot.GetWithOptions(xml, path, opts)
+	element, _ = locateElement(xml, path, result, opts)
+
+	if !strings.Contains(xml, cdataStart) {
+		return result, element, false
+	}
+	if result.IsArray() {
+		return cdataItems(result), element, false
+	}
+	if element == nil && (result.Type == xmldot.Element || result.Type == xmldot.Null) {
+		// The library's Raw may not match the source when CDATA was mis-scanned
+		if resolved, ok := resolveElement(xml, path, opts); ok {
+			element = resolved
+		}
+	}
+	if element == nil && result.Type == xmldot.Element && strings.Contains(result.Raw, cdataStart) {
+		// Matches of wildcard and filter paths keep their Raw intact
+		result.Str = cdataText(result.Raw)
+		return result, nil, true
+	}
+	if element == nil || !element.hasCDATA(xml) {
+		return result, element, false
+	}
+
+	inner := xml[element.innerStart:element.innerEnd]
+	return xmldot.Result{
+		Type: xmldot.Element,
+		Raw:  inner,
+		Str:  cdataText(inner),
+	}, element, true
+}
@@ -0,0 +1,200 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"syscall/js"
+
+	"github.com/netascode/xmldot"
+)
+
+// MaxRoundTripWarnings caps the warnings reported by roundTrip.
+const MaxRoundTripWarnings = 100
+
+// roundTrip re-serializes a document with xmldot's @pretty modifier, which
+// decodes and re-encodes every token, and reports what did not survive.
+// The playground's own formatting bindings keep markup verbatim; this shows
+// the losses of the library's serializer, such as namespace prefixes being
+// rewritten or CDATA sections turned into escaped text, before results of
+// @pretty or @ugly are trusted. Whitespace-only text and indentation are not
+// reported.
+// Args: xml (string)
+// Returns: map with result (the re-serialized document), stable (a second
+// pass gives the same document; @pretty adds whitespace on every pass, so
+// whitespace-only text is ignored), warnings (array of {kind: "serialize",
+// "doctype", "name", "attribute", "attributeOrder", "text", "comment",
+// "processingInstruction" or "cdata", path, message}; paths select the
+// element in the input) and truncated (more than MaxRoundTripWarnings
+// warnings) fields OR error field
+func roundTrip(this js.Value, args []js.Value) (result any) {
+	// Panic recovery with safe error return
+	defer func() {
+		if r := recover(); r != nil {
+			result = makeError(codeInternal, "Round trip failed due to resource limits or invalid input")
+		}
+	}()
+
+	// Validate argument count
+	if len(args) != 1 {
+		return makeError(codeInvalidArgument, "Expected 1 argument: xml")
+	}
+
+	doc, errResult := formatArg(args[0])
+	if errResult != nil {
+		return errResult
+	}
+
+	r := &roundTripper{differ: differ{a: doc}}
+
+	// @pretty returns its input unchanged when the decoder fails, so the
+	// failure is found here to tell it apart from a stable document
+	decoder := xml.NewDecoder(strings.NewReader(doc))
+	for {
+		if _, err := decoder.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			r.warn("serialize", "", fmt.Sprintf("xmldot cannot re-serialize the document: %v", err))
+			return r.response(doc, false)
+		}
+	}
+
+	pretty := xmldot.GetModifier("pretty")
+	r.b = pretty.Apply(xmldot.Result{Type: xmldot.Element, Raw: doc}).Raw
+	again := &differ{a: r.b, b: pretty.Apply(xmldot.Result{Type: xmldot.Element, Raw: r.b}).Raw}
+
+	if doctypeStart(r.a) >= 0 && doctypeStart(r.b) < 0 {
+		r.warn("doctype", "", "the DOCTYPE declaration was lost")
+	}
+	rootA, errA := parseOutline(r.a)
+	rootB, errB := parseOutline(r.b)
+	rootAgain, errAgain := parseOutline(again.b)
+	if errA != nil || errB != nil || errAgain != nil {
+		r.warn("serialize", "", "the re-serialized document could not be read back")
+		return r.response(r.b, false)
+	}
+	r.compare(rootA, rootB)
+	again.compareChildren(rootB, rootAgain)
+	return r.response(r.b, len(again.changes) == 0)
+}
+
+// roundTripper collects the warnings of roundTrip, comparing the input a
+// with the re-serialized document b. The differ's text normalization is
+// reused to compare character data.
+type roundTripper struct {
+	differ
+	warnings []any
+}
+
+// warn records a warning, or marks the report truncated once
+// MaxRoundTripWarnings is reached.
+func (r *roundTripper) warn(kind, path, message string) {
+	if len(r.warnings) == MaxRoundTripWarnings {
+		r.truncated = true
+		return
+	}
+	r.warnings = append(r.warnings, map[string]any{
+		"kind":    kind,
+		"path":    path,
+		"message": message,
+	})
+}
+
+// response builds the binding's result.
+func (r *roundTripper) response(output string, stable bool) map[string]any {
+	warnings := r.warnings
+	if warnings == nil {
+		warnings = []any{}
+	}
+	return map[string]any{
+		"result":    output,
+		"stable":    stable,
+		"warnings":  warnings,
+		"truncated": r.truncated,
+	}
+}
+
+// compare compares the comments, processing instructions and children of
+// two outline nodes in the same position. The serializer keeps every
+// element, so children are paired in order.
+func (r *roundTripper) compare(a, b *node) {
+	path := a.canonicalPath()
+	if lost := len(a.comments) - len(b.comments); lost > 0 {
+		r.warn("comment", path, fmt.Sprintf("%d comment(s) lost", lost))
+	}
+	if lost := len(a.procInsts) - len(b.procInsts); lost > 0 {
+		r.warn("processingInstruction", path, fmt.Sprintf("%d processing instruction(s) lost", lost))
+	}
+
+	for i, childA := range a.children {
+		if i == len(b.children) {
+			r.warn("name", childA.canonicalPath(), fmt.Sprintf("element %s was lost", childA.name))
+			continue
+		}
+		r.compareElements(childA, b.children[i])
+	}
+}
+
+// compareElements compares an element of the input with its re-serialized
+// counterpart.
+func (r *roundTripper) compareElements(a, b *node) {
+	path := a.canonicalPath()
+	if a.name != b.name {
+		r.warn("name", path, fmt.Sprintf("element %s is written as %s", a.name, b.name))
+	}
+
+	namesA, namesB := attributeNames(a), attributeNames(b)
+	attrsA, attrsB := a.attributeMap(), b.attributeMap()
+	changed := false
+	for _, name := range namesA {
+		if value, ok := attrsB[name]; !ok {
+			r.warn("attribute", path, fmt.Sprintf("attribute %s was lost or renamed", name))
+			changed = true
+		} else if value != attrsA[name] {
+			r.warn("attribute", path, fmt.Sprintf("attribute %s changed from %q to %q", name, attrsA[name], value))
+		}
+	}
+	for _, name := range namesB {
+		if _, ok := attrsA[name]; !ok {
+			r.warn("attribute", path, fmt.Sprintf("attribute %s was added", name))
+			changed = true
+		}
+	}
+	if !changed && strings.Join(namesA, " ") != strings.Join(namesB, " ") {
+		r.warn("attributeOrder", path, "attributes were reordered")
+	}
+
+	if ownCDATA(r.a, a) && !ownCDATA(r.b, b) {
+		r.warn("cdata", path, "CDATA sections were converted to escaped text")
+	}
+	if textA, textB := r.text(r.a, a), r.text(r.b, b); textA != textB {
+		r.warn("text", path, fmt.Sprintf("text changed from %q to %q", textA, textB))
+	}
+
+	r.compare(a, b)
+}
+
+// ownCDATA reports whether the content directly inside n, outside its child
+// elements, contains a CDATA section.
+func ownCDATA(doc string, n *node) bool {
+	offset := n.innerStart
+	for _, child := range n.children {
+		if strings.Contains(doc[offset:child.start], cdataStart) {
+			return true
+		}
+		offset = child.end
+	}
+	return strings.Contains(doc[offset:n.innerEnd], cdataStart)
+}
+
+// attributeNames returns the attribute names of n as written, in order.
+func attributeNames(n *node) []string {
+	names := make([]string, len(n.attrs))
+	for i, attr := range n.attrs {
+		names[i] = qualifiedName(attr.Name)
+	}
+	return names
+}
@@ -0,0 +1,126 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"syscall/js"
+
+	"github.com/netascode/xmldot"
+)
+
+// strictError checks doc against the well-formedness rules that xmldot's
+// validator tolerates, for callers that must refuse such input rather than
+// query it: a single document element, entity references that are
+// predefined or declared in the internal subset, valid characters and
+// character references, no '<' in attribute values, no "]]>" in text, the
+// XML declaration only at the start, and declared namespace prefixes. doc
+// must already pass xmldot's validator, which checks nesting and tag case.
+// The error points at the first violation.
+func strictError(doc string) *xmldot.ValidateError {
+	decoder := xml.NewDecoder(strings.NewReader(doc))
+	decoder.Strict = true
+	decoder.Entity = make(map[string]string)
+	for name := range declaredEntities(doc) {
+		decoder.Entity[name] = ""
+	}
+	decoder.CharsetReader = passThroughCharset
+
+	depth, roots := 0, 0
+	for {
+		offset := int(decoder.InputOffset())
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			message := err.Error()
+			var syntaxErr *xml.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				message = syntaxErr.Msg
+			}
+			return validateErrorAt(doc, int(decoder.InputOffset()), message)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				if roots++; roots > 1 {
+					return validateErrorAt(doc, offset, fmt.Sprintf("second root element '%s'", qualifiedName(t.Name)))
+				}
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.ProcInst:
+			if strings.EqualFold(t.Target, "xml") && offset != 0 {
+				return validateErrorAt(doc, offset, "XML declaration allowed only at the start of the document")
+			}
+		}
+	}
+
+	root, err := parseOutline(doc)
+	if err != nil {
+		return nil
+	}
+	return undeclaredPrefix(doc, root)
+}
+
+// undeclaredPrefix finds the first element or attribute below n whose
+// namespace prefix is not bound where it is used. The "xml" prefix is
+// always bound and "xmlns" only introduces declarations.
+func undeclaredPrefix(doc string, n *node) *xmldot.ValidateError {
+	for _, child := range n.children {
+		scope := child.inScopeNamespaces()
+		bound := func(prefix string) bool {
+			_, ok := scope[prefix]
+			return prefix == "" || prefix == "xml" || prefix == "xmlns" || ok
+		}
+
+		if prefix, _ := splitName(child.name); !bound(prefix) {
+			return validateErrorAt(doc, child.start, fmt.Sprintf("undeclared namespace prefix '%s'", prefix))
+		}
+		for _, token := range attributeTokens(doc[child.start:child.innerStart]) {
+			if prefix, _ := splitName(token.name); !bound(prefix) {
+				return validateErrorAt(doc, child.start+token.start, fmt.Sprintf("undeclared namespace prefix '%s'", prefix))
+			}
+		}
+		if err := undeclaredPrefix(doc, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// strictResponse converts a strict mode violation into an error response
+// that also carries its line and column.
+func strictResponse(err *xmldot.ValidateError) map[string]any {
+	response := makeError(codeMalformed, fmt.Sprintf("Strict mode: %s at line %d, column %d", err.Message, err.Line, err.Column))
+	response["line"] = err.Line
+	response["column"] = err.Column
+	return response
+}
+
+// validationStrict reads the optional second argument of the validation
+// bindings, an object whose strict field (boolean, default false) adds the
+// checks of strictError. Undefined or null selects the default.
+func validationStrict(args []js.Value) (bool, error) {
+	if len(args) < 2 || args[1].IsUndefined() || args[1].IsNull() {
+		return false, nil
+	}
+	if args[1].Type() != js.TypeObject {
+		return false, fmt.Errorf("Second argument (options) must be an object")
+	}
+	strict := args[1].Get("strict")
+	if strict.IsUndefined() {
+		return false, nil
+	}
+	if strict.Type() != js.TypeBoolean {
+		return false, fmt.Errorf("Option strict must be a boolean")
+	}
+	return strict.Bool(), nil
+}
@@ -0,0 +1,52 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"strings"
+	"syscall/js"
+	"testing"
+)
+
+func TestStrictMode(t *testing.T) {
+	tests := []struct {
+		name, xml, message string
+		line, column       int // columns count from 0; decoder errors point past the token
+	}{
+		{"second root", "<r/>\n<s/>", "second root element 's'", 2, 0},
+		{"undeclared entity", "<r>\n  <a>&x;</a>\n</r>", "invalid character entity &x;", 2, 8},
+		{"invalid character reference", "<r>&#0;</r>", "illegal character code U+0000", 1, 7},
+		{"'<' in attribute", `<r a="<"/>`, "unescaped < inside quoted string", 1, 7},
+		{"']]>' in text", "<r>a]]>b</r>", "unescaped ]]> not in CDATA section", 1, 7},
+		{"late declaration", `<r><?xml version="1.0"?></r>`, "XML declaration allowed only at the start of the document", 1, 3},
+		{"undeclared prefix", `<r><a x:b="1"/></r>`, "undeclared namespace prefix 'x'", 1, 6},
+	}
+	strict := map[string]any{"strict": true}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := call(t, executeQuery, tt.xml, "r", strict)
+			if response["code"] != codeMalformed || !strings.Contains(response["error"].(string), tt.message) ||
+				response["line"] != tt.line || response["column"] != tt.column {
+				t.Errorf("got %v, want %q at %d:%d", response, tt.message, tt.line, tt.column)
+			}
+			if response := call(t, executeQuery, tt.xml, "r"); response["error"] != nil {
+				t.Errorf("lenient mode: got %v, want a result", response)
+			}
+
+			if valid := call(t, validateXMLDetailed, tt.xml, strict); valid["valid"] != false || valid["line"] != tt.line {
+				t.Errorf("validateXMLDetailed = %v, want invalid at line %d", valid, tt.line)
+			}
+			if valid := validateXML(js.Undefined(), []js.Value{js.ValueOf(tt.xml), js.ValueOf(strict)}); valid != false {
+				t.Errorf("validateXML = %v, want false", valid)
+			}
+		})
+	}
+}
+
+func TestStrictModeAcceptsWellFormed(t *testing.T) {
+	xml := `<?xml version="1.0"?><!DOCTYPE r [<!ENTITY e "x">]><r xmlns:x="u"><x:a x:b="&e;&amp;&#65;">]]&gt;</x:a></r>`
+	response := mustQuery(t, xml, "r.x:a", map[string]any{"strict": true})
+	if response["value"] != "]]>" {
+		t.Errorf("value = %v, want ]]>", response["value"])
+	}
+}
@@ -0,0 +1,129 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"syscall/js"
+
+	"github.com/netascode/xmldot"
+)
+
+// MaxSuggestions caps the names returned by suggestPaths.
+const MaxSuggestions = 50
+
+// suggestPaths suggests the next segment of a partial path from the names
+// present in the document: child elements of the elements the parent path
+// matches, and "@name" attributes of a located parent element.
+// Args: xml (string), partialPath (string; may be empty or end with ".")
+// Returns: map with sorted suggestions (the names completing the last
+// segment) and truncated when more than MaxSuggestions matched OR error field
+func suggestPaths(this js.Value, args []js.Value) (result any) {
+	// Panic recovery with safe error return
+	defer func() {
+		if r := recover(); r != nil {
+			result = makeError(codeInternal, "Failed to suggest paths")
+		}
+	}()
+
+	// Validate argument count
+	if len(args) != 2 {
+		return makeError(codeInvalidArgument, "Expected 2 arguments: xml and partialPath")
+	}
+
+	// An empty partial path is allowed here, so validate without queryArgs
+	if args[0].Type() != js.TypeString {
+		return makeError(codeInvalidArgument, "First argument (xml) must be a string")
+	}
+	if args[1].Type() != js.TypeString {
+		return makeError(codeInvalidArgument, "Second argument (partialPath) must be a string")
+	}
+	xml := args[0].String()
+	if xmlLen := len(xml); xmlLen > xmlSizeLimit {
+		return makeError(codeTooLarge, fmt.Sprintf("XML too large (%d bytes, max %d)", xmlLen, xmlSizeLimit))
+	}
+	partial := strings.TrimLeft(args[1].String(), " \t")
+	if pathLen := len(partial); pathLen > querySizeLimit {
+		return makeError(codeTooLarge, fmt.Sprintf("Query too large (%d bytes, max %d)", pathLen, querySizeLimit))
+	}
+
+	suggestions := []any{}
+	names, truncated := nextSegments(xml, partial)
+	for _, name := range names {
+		suggestions = append(suggestions, name)
+	}
+	response := map[string]any{
+		"suggestions": suggestions,
+	}
+	if truncated {
+		response["truncated"] = true
+	}
+	return response
+}
+
+// nextSegments returns the sorted names that complete the last segment of
+// partial, at most MaxSuggestions of them.
+func nextSegments(xml, partial string) (names []string, truncated bool) {
+	parentPath, prefix, modifiers := cutLastSegment(partial)
+	if modifiers != "" || isMultipath(partial) {
+		return nil, false
+	}
+
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if strings.HasPrefix(name, prefix) && name != prefix && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	if parentPath == "" {
+		if root, err := parseOutline(xml); err == nil {
+			for _, child := range root.children {
+				add(child.name)
+			}
+		}
+	} else {
+		opts := xmldot.DefaultOptions()
+		parent := xmldot.GetWithOptions(xml, resolveNegativeIndexes(xml, parentPath, opts), opts)
+		parents := []xmldot.Result{parent}
+		if parent.IsArray() {
+			parents = parent.Results
+		}
+		if allPath, ok := strings.CutSuffix(parentPath, ".#"); ok {
+			// "path.#." continues from every match of path; the library
+			// returns a count for "path.#", so read the siblings instead
+			parents = nil
+			if first, ok := resolveElement(xml, allPath, opts); ok {
+				for _, sibling := range first.parent.childrenNamed(first.name, opts) {
+					parents = append(parents, xmldot.Result{Type: xmldot.Element, Raw: xml[sibling.innerStart:sibling.innerEnd]})
+				}
+			}
+		}
+		for _, match := range parents {
+			if match.Type != xmldot.Element {
+				continue
+			}
+			if content, err := parseOutline(match.Raw); err == nil {
+				for _, child := range content.children {
+					add(child.name)
+				}
+			}
+		}
+		if element, ok := locateElement(xml, parentPath, parent, opts); ok {
+			for _, attr := range element.attrs {
+				if attr.Name.Space != "xmlns" && !(attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+					add("@" + qualifiedName(attr.Name))
+				}
+			}
+		}
+	}
+
+	sort.Strings(names)
+	if len(names) > MaxSuggestions {
+		return names[:MaxSuggestions], true
+	}
+	return names, false
+}
@@ -0,0 +1,99 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/netascode/xmldot"
+)
+
+// duplicateAttribute finds the first start tag in doc that repeats an
+// attribute name, which xmldot's validator does not check. The error points
+// at the repeated attribute.
+func duplicateAttribute(doc string) *xmldot.ValidateError {
+	root, err := parseOutline(doc)
+	if err != nil {
+		return nil
+	}
+
+	var found *xmldot.ValidateError
+	var walk func(n *node)
+	walk = func(n *node) {
+		for _, child := range n.children {
+			if found != nil {
+				return
+			}
+			seen := make(map[string]bool, len(child.attrs))
+			for _, token := range attributeTokens(doc[child.start:child.innerStart]) {
+				if seen[token.name] {
+					found = validateErrorAt(doc, child.start+token.start, fmt.Sprintf("duplicate attribute '%s'", token.name))
+					return
+				}
+				seen[token.name] = true
+			}
+			walk(child)
+		}
+	}
+	walk(root)
+	return found
+}
+
+// unterminatedAttribute finds an attribute value whose closing quote is
+// missing. xmldot reports that at the end of the document, where it ran out
+// of input; the error returned here points at the attribute instead.
+// Comments, CDATA sections, processing instructions and declarations are
+// skipped, and a '>' inside a quoted value does not end the tag.
+func unterminatedAttribute(doc string) *xmldot.ValidateError {
+	skip := []struct{ start, end string }{
+		{"<!--", "-->"},
+		{cdataStart, cdataEnd},
+		{"<?", "?>"},
+		{"<!", ">"},
+	}
+
+	i := 0
+scan:
+	for {
+		next := strings.IndexByte(doc[i:], '<')
+		if next < 0 {
+			return nil
+		}
+		i += next
+
+		for _, markup := range skip {
+			if strings.HasPrefix(doc[i:], markup.start) {
+				end := strings.Index(doc[i+len(markup.start):], markup.end)
+				if end < 0 {
+					return nil
+				}
+				i += len(markup.start) + end + len(markup.end)
+				continue scan
+			}
+		}
+
+		for i++; i < len(doc) && doc[i] != '>'; i++ {
+			if doc[i] != '"' && doc[i] != '\'' {
+				continue
+			}
+			end := strings.IndexByte(doc[i+1:], doc[i])
+			if end < 0 {
+				name := strings.TrimRight(doc[:i], " \t\r\n=")
+				nameStart := strings.LastIndexAny(name, " \t\r\n") + 1
+				return validateErrorAt(doc, nameStart, fmt.Sprintf("unterminated value of attribute '%s'", name[nameStart:]))
+			}
+			i += end + 1
+		}
+	}
+}
+
+// validateErrorAt builds a ValidateError for a byte offset in doc, counting
+// lines and columns as xmldot does: lines from 1, columns from 0 in bytes.
+func validateErrorAt(doc string, offset int, message string) *xmldot.ValidateError {
+	return &xmldot.ValidateError{
+		Line:    strings.Count(doc[:offset], "\n") + 1,
+		Column:  offset - (strings.LastIndexByte(doc[:offset], '\n') + 1),
+		Message: message,
+	}
+}
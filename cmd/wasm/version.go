@@ -0,0 +1,51 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"runtime/debug"
+	"syscall/js"
+)
+
+// xmldotModule is the module path of the linked query library.
+const xmldotModule = "github.com/netascode/xmldot"
+
+// buildCommit and buildDate are set at link time, e.g. by the Makefile with
+// -ldflags "-X main.buildDate=...". The commit defaults to the revision the
+// go command records when building from a checkout.
+var (
+	buildCommit string
+	buildDate   string
+)
+
+// getVersion reports what is running, so behavior changes can be tied to a
+// library release and a playground build.
+// Args: none
+// Returns: map with version (the linked xmldot module version, e.g.
+// "v0.4.1", or "unknown"), playgroundCommit (the revision of this
+// playground, not of xmldot) and buildDate (both empty when not recorded)
+// fields
+func getVersion(this js.Value, args []js.Value) any {
+	version, commit := "unknown", buildCommit
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path != xmldotModule {
+				continue
+			}
+			version = dep.Version
+			if dep.Replace != nil {
+				version = dep.Replace.Version
+			}
+		}
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" && commit == "" {
+				commit = setting.Value
+			}
+		}
+	}
+	return map[string]any{
+		"version":          version,
+		"playgroundCommit": commit,
+		"buildDate":        buildDate,
+	}
+}
@@ -0,0 +1,20 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"syscall/js"
+	"testing"
+)
+
+func TestGetVersionFields(t *testing.T) {
+	response := getVersion(js.Undefined(), nil).(map[string]any)
+	for _, field := range []string{"version", "playgroundCommit", "buildDate"} {
+		if _, ok := response[field].(string); !ok {
+			t.Errorf("%s = %v, want a string", field, response[field])
+		}
+	}
+	if _, ok := response["commit"]; ok {
+		t.Errorf("commit is reported as playgroundCommit")
+	}
+}
@@ -0,0 +1,763 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"strconv"
+	"strings"
+	"syscall/js"
+)
+
+// xpathToQuery translates a common subset of XPath 1.0 into an XMLDOT path,
+// for users who type XPath into the playground. Translated:
+//   - "/" and "//" between steps ("//" becomes "**"); a leading "/" is dropped
+//   - element names (with prefixes), "*", "*:local" (XPath 2.0, any
+//     namespace), "@name", "@*", and the child:: and attribute:: axes
+//   - "." (dropped) and ".." (as parent())
+//   - comment() and processing-instruction() node tests, and text() as the
+//     final step
+//   - predicates [n], [last()] and [last()-k] as positional steps, and
+//     position() compared with an integer or last()-k, optionally after
+//     "mod n", as position() predicates
+//   - predicates [not(@a)], [@*] and [not(@*)] as attribute predicates
+//   - predicates [@a], [child], [@a='v'], [child>1500] and [.='v'] with =,
+//     !=, <, <=, > and >= against a string or number literal, and contains()
+//     and starts-with() against a string literal, as "#(...)#" filters;
+//     normalize-space(.) may stand for "."
+//   - "and", "or" and parentheses between those predicates, as && and ||
+//   - count(path) and normalize-space(path) around a whole path
+//
+// Everything else is rejected with an explanation: other axes, unions,
+// other functions, variables and arithmetic.
+// Args: xpath (string)
+// Returns: map with path field OR error field
+func xpathToQuery(this js.Value, args []js.Value) (result any) {
+	// Panic recovery with safe error return
+	defer func() {
+		if r := recover(); r != nil {
+			result = makeError(codeInternal, "Failed to translate XPath")
+		}
+	}()
+
+	// Validate argument count
+	if len(args) != 1 {
+		return makeError(codeInvalidArgument, "Expected 1 argument: xpath")
+	}
+	if args[0].Type() != js.TypeString {
+		return makeError(codeInvalidArgument, "First argument (xpath) must be a string")
+	}
+
+	xpath, errResult := checkPath(args[0].String())
+	if errResult != nil {
+		return errResult
+	}
+
+	path, err := translateXPath(xpath)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return map[string]any{
+		"path": path,
+	}
+}
+
+// translateXPath translates an XPath expression; see xpathToQuery.
+func translateXPath(xpath string) (string, error) {
+	xpath = strings.TrimSpace(xpath)
+	if inner, ok := cutXPathFunction(xpath, "count"); ok {
+		path, err := translateXPath(inner)
+		if err != nil {
+			return "", err
+		}
+		return path + ".#", nil
+	}
+	if inner, ok := cutXPathFunction(xpath, "normalize-space"); ok {
+		path, err := translateXPath(inner)
+		if err != nil {
+			return "", err
+		}
+		return normalizeSpaceOpen + path + ")", nil
+	}
+
+	if parts, err := splitXPath(xpath, '|'); err != nil {
+		return "", err
+	} else if len(parts) > 1 {
+		return "", newError(codeInvalidPath, "XPath unions (|) are not supported; run each path as its own query")
+	}
+	steps, _ := splitXPath(xpath, '/')
+	if len(steps) > 0 && steps[0] == "" {
+		// Absolute path: XMLDOT paths always start at the document
+		steps = steps[1:]
+	}
+
+	var segments []string
+	for i := 0; i < len(steps); i++ {
+		step := strings.TrimSpace(steps[i])
+		if step == "" {
+			// The empty step between the slashes of "//"
+			if i == len(steps)-1 {
+				return "", newError(codeInvalidPath, "XPath must not end with '/'")
+			}
+			segments = append(segments, "**")
+			continue
+		}
+		if step == textTest && i == len(steps)-1 && len(segments) > 0 {
+			segments = append(segments, textTest)
+			continue
+		}
+
+		translated, err := translateXPathStep(step)
+		if err != nil {
+			return "", err
+		}
+		segments = append(segments, translated...)
+	}
+	if len(segments) == 0 {
+		return "", newError(codeInvalidPath, "XPath selects no element")
+	}
+	return strings.Join(segments, "."), nil
+}
+
+// translateXPathStep translates one location step and its predicates into
+// path segments.
+func translateXPathStep(step string) ([]string, error) {
+	test, predicates, err := splitPredicates(step)
+	if err != nil {
+		return nil, err
+	}
+
+	if axis, rest, found := strings.Cut(test, "::"); found {
+		switch axis {
+		case "child":
+			test = rest
+		case "attribute":
+			test = "@" + rest
+		default:
+			return nil, newError(codeInvalidPath, "XPath axis %s:: is not supported", axis)
+		}
+	}
+
+	var segment string
+	switch {
+	case test == "." && len(predicates) == 0:
+		return nil, nil
+	case test == "..":
+		segment = parentTest
+	case test == commentTest || strings.HasPrefix(test, piTestPrefix):
+		segment = test
+	case strings.Contains(test, "("):
+		return nil, newError(codeInvalidPath, "XPath function or node test %q is not supported", test)
+	case test == "*" || test == "@*":
+		segment = test
+	case strings.HasPrefix(test, anyNamespacePrefix) && isXMLName(test[2:]):
+		segment = anyNamespacePrefix + escapeSegment(test[2:])
+	case strings.HasPrefix(test, "@") && isXMLName(test[1:]):
+		segment = "@" + escapeSegment(test[1:])
+	case isXMLName(test):
+		segment = escapeSegment(test)
+	default:
+		return nil, newError(codeInvalidPath, "Invalid XPath step %q", test)
+	}
+	if len(predicates) > 0 && (strings.HasPrefix(segment, "@") || strings.Contains(segment, "(")) {
+		return nil, newError(codeInvalidPath, "XPath predicates are only supported on element steps")
+	}
+
+	segments := []string{segment}
+	for _, predicate := range predicates {
+		predicate = strings.TrimSpace(predicate)
+		if _, _, err := parsePosition(predicate); err == nil {
+			segments[len(segments)-1] += "[" + predicate + "]"
+			continue
+		}
+		if strings.HasPrefix(predicate, positionFunction) {
+			if _, err := parsePositionTest(predicate); err != nil {
+				return nil, err
+			}
+			segments[len(segments)-1] += "[" + predicate + "]"
+			continue
+		}
+		if name, negate, ok := cutAttributePredicate("[" + predicate + "]"); ok && (negate || name == "*") {
+			segments[len(segments)-1] += "[" + predicate + "]"
+			continue
+		}
+		condition, err := translateXPathCondition(predicate)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, "#("+condition+")#")
+	}
+	return segments, nil
+}
+
+// translateXPathCondition translates a non-positional predicate into a
+// filter condition. "or" and "and" become || and &&, which share XPath's
+// precedence, and parentheses are kept.
+func translateXPathCondition(predicate string) (string, error) {
+	predicate = strings.TrimSpace(predicate)
+	for _, keyword := range []struct{ xpath, xmldot string }{
+		{"or", conditionOr}, {"and", conditionAnd},
+	} {
+		operands, err := splitXPathKeyword(predicate, keyword.xpath)
+		if err != nil {
+			return "", err
+		}
+		if len(operands) < 2 {
+			continue
+		}
+		for i, operand := range operands {
+			if operands[i], err = translateXPathCondition(operand); err != nil {
+				return "", err
+			}
+		}
+		return strings.Join(operands, " "+keyword.xmldot+" "), nil
+	}
+	if inner, ok := cutXPathParens(predicate); ok {
+		condition, err := translateXPathCondition(inner)
+		if strings.Contains(condition, conditionAnd) || strings.Contains(condition, conditionOr) {
+			condition = "(" + condition + ")"
+		}
+		return condition, err
+	}
+
+	for _, function := range []struct{ name, pattern string }{
+		{"contains", "*%s*"},
+		{"starts-with", "%s*"},
+	} {
+		inner, ok := cutXPathFunction(predicate, function.name)
+		if !ok {
+			continue
+		}
+		args, err := splitXPath(inner, ',')
+		if err != nil || len(args) != 2 {
+			return "", newError(codeInvalidPath, "XPath %s() takes two arguments", function.name)
+		}
+		operand, err := translateXPathOperand(args[0])
+		if err != nil {
+			return "", err
+		}
+		text, ok := xpathString(args[1])
+		if !ok || strings.ContainsAny(text, "*?") {
+			return "", newError(codeInvalidPath, "XPath %s() needs a string literal without * or ?", function.name)
+		}
+		return operand + "%" + quoteFilterValue(strings.ReplaceAll(function.pattern, "%s", text)), nil
+	}
+
+	for _, op := range []struct{ xpath, xmldot string }{
+		{"!=", "!="}, {"<=", "<="}, {">=", ">="}, {"=", "=="}, {"<", "<"}, {">", ">"},
+	} {
+		parts, err := splitXPath(predicate, op.xpath[0])
+		if err != nil || len(parts) < 2 {
+			continue
+		}
+		left := parts[0]
+		right := strings.Join(parts[1:], op.xpath[:1])
+		if len(op.xpath) == 2 {
+			if !strings.HasPrefix(right, op.xpath[1:]) {
+				continue
+			}
+			right = right[1:]
+		} else if strings.HasSuffix(left, "!") || strings.HasSuffix(left, "<") || strings.HasSuffix(left, ">") {
+			continue
+		}
+
+		operand, err := translateXPathOperand(left)
+		if err != nil {
+			return "", err
+		}
+		right = strings.TrimSpace(right)
+		if text, ok := xpathString(right); ok {
+			return operand + op.xmldot + quoteFilterValue(text), nil
+		}
+		if _, err := strconv.ParseFloat(right, 64); err == nil {
+			return operand + op.xmldot + right, nil
+		}
+		return "", newError(codeInvalidPath, "XPath comparisons are only supported against a string or number literal")
+	}
+
+	if strings.ContainsAny(predicate, "()$+") || strings.TrimSpace(predicate) == selfValue {
+		return "", newError(codeInvalidPath, "XPath predicate [%s] is not supported", predicate)
+	}
+	return translateXPathOperand(predicate)
+}
+
+// translateXPathOperand translates the attribute or relative child path a
+// predicate tests, such as "@id" or "config/mtu". Inside a filter the path
+// separator is an escaped dot, so names containing dots cannot be tested.
+func translateXPathOperand(operand string) (string, error) {
+	operand = strings.TrimSpace(operand)
+	if operand == selfValue || operand == selfValueNormalize {
+		return operand, nil
+	}
+	if strings.Contains(operand, "(") {
+		return "", newError(codeInvalidPath, "XPath function %q is not supported in predicates", operand)
+	}
+	steps := strings.Split(strings.TrimPrefix(operand, "child::"), "/")
+	for i, step := range steps {
+		name := strings.TrimPrefix(step, "@")
+		if !isXMLName(name) || strings.Contains(name, ".") || (name != step && i != len(steps)-1) {
+			return "", newError(codeInvalidPath, "XPath predicate operand %q is not supported; use @attribute or a child path", operand)
+		}
+	}
+	return strings.Join(steps, "\\."), nil
+}
+
+// splitXPath splits s on sep outside brackets, parentheses and string
+// literals, reporting unbalanced brackets and unterminated literals.
+func splitXPath(s string, sep byte) ([]string, error) {
+	var parts []string
+	depth := 0
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '(':
+			depth++
+		case c == ']' || c == ')':
+			if depth--; depth < 0 {
+				return nil, newError(codeInvalidPath, "Unexpected '%c' in XPath", c)
+			}
+		case c == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	if quote != 0 {
+		return nil, newError(codeInvalidPath, "Unterminated string literal in XPath")
+	}
+	if depth != 0 {
+		return nil, newError(codeInvalidPath, "Unclosed bracket in XPath")
+	}
+	return append(parts, s[start:]), nil
+}
+
+// splitPredicates separates a step's node test from its bracketed predicates.
+func splitPredicates(step string) (test string, predicates []string, err error) {
+	open := strings.IndexByte(step, '[')
+	if open < 0 {
+		return step, nil, nil
+	}
+	test = strings.TrimSpace(step[:open])
+
+	rest := step[open:]
+	for rest != "" {
+		if rest[0] != '[' {
+			return "", nil, newError(codeInvalidPath, "Unexpected %q after XPath predicate", rest)
+		}
+		// splitXPath has already checked the brackets are balanced
+		end, depth := 0, 0
+		var quote byte
+		for i := 0; end == 0; i++ {
+			switch c := rest[i]; {
+			case quote != 0:
+				if c == quote {
+					quote = 0
+				}
+			case c == '"' || c == '\'':
+				quote = c
+			case c == '[' || c == '(':
+				depth++
+			case c == ']' || c == ')':
+				if depth--; depth == 0 {
+					end = i
+				}
+			}
+		}
+		predicates = append(predicates, rest[1:end])
+		rest = strings.TrimSpace(rest[end+1:])
+	}
+	return test, predicates, nil
+}
+
+// cutXPathFunction reports whether s is a call of the named function and
+// returns its argument list.
+func cutXPathFunction(s, name string) (string, bool) {
+	inner, ok := strings.CutPrefix(s, name+"(")
+	if !ok || !strings.HasSuffix(inner, ")") {
+		return "", false
+	}
+	return strings.TrimSuffix(inner, ")"), true
+}
+
+// xpathString returns the content of a quoted XPath string literal.
+func xpathString(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || (s[0] != '"' && s[0] != '\'') || s[len(s)-1] != s[0] {
+		return "", false
+	}
+	return s[1 : len(s)-1], true
+}
+
+// splitXPathKeyword splits an expression on the operator keyword ("and" or
+// "or") written between spaces outside brackets and literals.
+func splitXPathKeyword(s, keyword string) ([]string, error) {
+	words, err := splitXPath(s, ' ')
+	if err != nil {
+		return nil, err
+	}
+	var operands []string
+	start := 0
+	for i, word := range words {
+		if word == keyword {
+			operands = append(operands, strings.Join(words[start:i], " "))
+			start = i + 1
+		}
+	}
+	operands = append(operands, strings.Join(words[start:], " "))
+	for _, operand := range operands {
+		if strings.TrimSpace(operand) == "" && len(operands) > 1 {
+			return nil, newError(codeInvalidPath, "XPath %q needs an operand on each side", keyword)
+		}
+	}
+	return operands, nil
+}
+
+// cutXPathParens returns the expression inside parentheses enclosing all of
+// s, as in "(a or b)" but not "(a) or (b)".
+func cutXPathParens(s string) (string, bool) {
+	if !strings.HasPrefix(s, "(") || !strings.HasSuffix(s, ")") {
+		return "", false
+	}
+	inner := s[1 : len(s)-1]
+	if _, err := splitXPath(inner, ','); err != nil {
+		return "", false
+	}
+	return inner, true
+}
+
+// quoteFilterValue quotes a filter value with double quotes, or single
+// quotes when it contains a double quote. Dots are escaped so the path
+// parser does not split the value.
+func quoteFilterValue(value string) string {
+	value = strings.ReplaceAll(value, ".", "\\.")
+	if strings.Contains(value, `"`) {
+		return "'" + value + "'"
+	}
+	return `"` + value + `"`
+}
+
+// queryToXPath translates an XMLDOT path into the equivalent XPath 1.0
+// expression, for copying queries into other tools. The inverse of
+// xpathToQuery, it translates element and attribute steps, "*", "*:local"
+// (as *[local-name()='local']), "@*", "**" (as "//"), parent() (as ".."),
+// "%" (as text()), text(), comment() and processing-instruction() node
+// tests, indexes and positional steps (as [n], [last()] or [last()-k]),
+// position() predicates, "#" (as count() at the end of a path), "#(...)#"
+// and "#(...)" filters with ==, !=, <, <=, >, >=, % and !% conditions
+// combined with && and || (as "and" and "or"), and normalize-space().
+// Name patterns other than "*", "%" patterns other than "text*" and
+// "*text*", modifiers and multipath queries have no XPath equivalent and
+// are rejected.
+// Args: path (string)
+// Returns: map with xpath field OR error field (with position when the
+// path itself is invalid)
+func queryToXPath(this js.Value, args []js.Value) (result any) {
+	// Panic recovery with safe error return
+	defer func() {
+		if r := recover(); r != nil {
+			result = makeError(codeInternal, "Failed to translate path")
+		}
+	}()
+
+	// Validate argument count
+	if len(args) != 1 {
+		return makeError(codeInvalidArgument, "Expected 1 argument: path")
+	}
+	if args[0].Type() != js.TypeString {
+		return makeError(codeInvalidArgument, "First argument (path) must be a string")
+	}
+
+	path, errResult := checkPath(args[0].String())
+	if errResult != nil {
+		return errResult
+	}
+	if isMultipath(path) {
+		return makeError(codeInvalidPath, "Multipath queries have no XPath equivalent; translate each path on its own")
+	}
+
+	explanation, explainErr := explainPath(path)
+	if explainErr != nil {
+		return explainErr.response()
+	}
+	xpath, err := translateQuery(explanation)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return map[string]any{
+		"xpath": xpath,
+	}
+}
+
+// translateQuery translates a path described by explainPath into XPath;
+// see queryToXPath.
+func translateQuery(explanation map[string]any) (string, error) {
+	if modifiers := explanation["modifiers"].([]any); len(modifiers) > 0 {
+		name := modifiers[0].(map[string]any)["name"]
+		return "", newError(codeInvalidPath, "Modifier %s has no XPath equivalent", name)
+	}
+
+	var xpath strings.Builder
+	descendant, count := false, false
+	for _, item := range explanation["segments"].([]any) {
+		segment := item.(map[string]any)
+		text := segment["text"].(string)
+
+		var step, predicate string
+		switch segment["kind"] {
+		case "descendant":
+			descendant = true
+			continue
+		case "allMatches":
+			// XPath steps always select all matches
+			continue
+		case "count":
+			// explainPath only reports a final "#" as count
+			count = true
+			continue
+		case "element":
+			step = segment["name"].(string)
+			if segment["anyNamespace"] == true {
+				step = "*[local-name()='" + step + "']"
+			}
+		case "attribute":
+			step = "@" + segment["name"].(string)
+			if uri, ok := segment["namespace"].(string); ok {
+				literal, err := xpathLiteral(uri)
+				if err != nil {
+					return "", err
+				}
+				step = "@*[local-name()='" + segment["name"].(string) + "' and namespace-uri()=" + literal + "]"
+			}
+		case "attributeWildcard":
+			step = "@*"
+		case "namespaceDeclarations":
+			step = "namespace::*"
+		case "text", "textNodes":
+			step = "text()"
+		case "parent":
+			step = ".."
+		case "followingSibling":
+			step = "following-sibling::*[1]"
+		case "precedingComment":
+			return "", newError(codeInvalidPath, "%s has no XPath 1.0 equivalent", precedingCommentTest)
+		case "comment", "processingInstruction":
+			step, _, _ = cutPosition(text)
+		case "wildcard":
+			if base, _, _ := cutPosition(text); base != "*" {
+				return "", newError(codeInvalidPath, "Name pattern %q has no XPath equivalent; only * is supported", base)
+			}
+			step = "*"
+		case "index":
+			index := segment["index"].(int)
+			if index >= 0 {
+				predicate = "[" + strconv.Itoa(index+1) + "]"
+			} else if index == -1 {
+				predicate = "[last()]"
+			} else {
+				predicate = "[last()-" + strconv.Itoa(-index-1) + "]"
+			}
+		case "filter":
+			condition, err := translateFilterCondition(segment["condition"].(string))
+			if err != nil {
+				return "", err
+			}
+			predicate = "[" + condition + "]"
+			if !segment["all"].(bool) {
+				predicate += "[1]"
+			}
+		}
+		if position, ok := segment["position"].(string); ok {
+			if strings.HasPrefix(position, positionFunction) {
+				position = strings.Replace(position, "==", "=", 1)
+			}
+			predicate += "[" + position + "]"
+		}
+
+		if step == "" {
+			// Indexes and filters apply to the step before them
+			if xpath.Len() == 0 || descendant {
+				return "", newError(codeInvalidPath, "Segment %q must follow an element step", text)
+			}
+			xpath.WriteString(predicate)
+			continue
+		}
+		if descendant {
+			xpath.WriteString("//")
+			descendant = false
+		} else {
+			xpath.WriteString("/")
+		}
+		xpath.WriteString(step + predicate)
+	}
+	if descendant {
+		return "", newError(codeInvalidPath, "'**' must be followed by a step")
+	}
+	if xpath.Len() == 0 {
+		return "", newError(codeInvalidPath, "Path has no steps to translate")
+	}
+
+	result := xpath.String()
+	if count {
+		result = "count(" + result + ")"
+	}
+	if _, ok := explanation["function"]; ok {
+		result = "normalize-space(" + result + ")"
+	}
+	return result, nil
+}
+
+// translateFilterCondition translates the condition of a "#(...)" filter
+// into an XPath predicate expression.
+func translateFilterCondition(condition string) (string, error) {
+	c, _, boolean, err := cutBooleanFilter("#(" + condition + ")")
+	if err != nil {
+		return "", err
+	}
+	if boolean {
+		return translateBooleanCondition(c)
+	}
+
+	for _, op := range []string{"==", "!=", "<=", ">=", "!%", "%", "<", ">"} {
+		at := indexOutsideQuotes(condition, op)
+		if at < 0 {
+			continue
+		}
+		operand, err := filterOperandXPath(condition[:at])
+		if err != nil {
+			return "", err
+		}
+
+		value := strings.TrimSpace(condition[at+len(op):])
+		quoted := len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0]
+		if quoted {
+			value = value[1 : len(value)-1]
+		}
+		value = unescapePath(value)
+
+		if op == "%" || op == "!%" {
+			test, err := patternXPath(operand, value)
+			if err != nil {
+				return "", err
+			}
+			if op == "!%" {
+				return "not(" + test + ")", nil
+			}
+			return test, nil
+		}
+
+		if op == "==" {
+			op = "="
+		}
+		if _, err := strconv.ParseFloat(value, 64); err == nil && !quoted {
+			return operand + op + value, nil
+		}
+		literal, err := xpathLiteral(value)
+		if err != nil {
+			return "", err
+		}
+		return operand + op + literal, nil
+	}
+	return filterOperandXPath(condition)
+}
+
+// translateBooleanCondition translates a filter condition combining
+// conditions with && and || into "and" and "or". A disjunction inside a
+// conjunction is parenthesized, as "&&" binds more tightly in both.
+func translateBooleanCondition(c *condition) (string, error) {
+	if c.atom != "" {
+		return translateFilterCondition(c.atom)
+	}
+	keyword := " and "
+	if c.or {
+		keyword = " or "
+	}
+	operands := make([]string, len(c.operands))
+	for i, operand := range c.operands {
+		translated, err := translateBooleanCondition(operand)
+		if err != nil {
+			return "", err
+		}
+		if c.and && operand.or {
+			translated = "(" + translated + ")"
+		}
+		operands[i] = translated
+	}
+	return strings.Join(operands, keyword), nil
+}
+
+// filterOperandXPath translates the path a filter condition tests, whose
+// steps are separated by escaped or plain dots.
+func filterOperandXPath(operand string) (string, error) {
+	operand = strings.TrimSpace(operand)
+	if operand == "" {
+		return "", newError(codeInvalidPath, "Filter condition has no path to test")
+	}
+	if operand == selfValue || operand == selfValueNormalize {
+		return operand, nil
+	}
+	operand = strings.ReplaceAll(operand, "\\.", ".")
+	return strings.ReplaceAll(operand, ".", "/"), nil
+}
+
+// patternXPath translates a "%" pattern match into contains(), starts-with()
+// or an equality test, the only patterns XPath 1.0 can express.
+func patternXPath(operand, pattern string) (string, error) {
+	leading := strings.HasPrefix(pattern, "*")
+	trailing := strings.HasSuffix(pattern, "*") && pattern != "*"
+	inner := strings.TrimSuffix(strings.TrimPrefix(pattern, "*"), "*")
+	if inner == "" || strings.ContainsAny(inner, "*?") || (leading && !trailing) {
+		return "", newError(codeInvalidPath, "Pattern %q has no XPath equivalent; only text, text* and *text* are supported", pattern)
+	}
+	literal, err := xpathLiteral(inner)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case leading:
+		return "contains(" + operand + "," + literal + ")", nil
+	case trailing:
+		return "starts-with(" + operand + "," + literal + ")", nil
+	default:
+		return operand + "=" + literal, nil
+	}
+}
+
+// xpathLiteral quotes value as an XPath string literal. XPath 1.0 has no
+// escapes, so a value containing both quote characters cannot be written.
+func xpathLiteral(value string) (string, error) {
+	switch {
+	case !strings.Contains(value, "'"):
+		return "'" + value + "'", nil
+	case !strings.Contains(value, `"`):
+		return `"` + value + `"`, nil
+	default:
+		return "", newError(codeInvalidPath, "Value %q contains both quote characters, which XPath 1.0 cannot express", value)
+	}
+}
+
+// indexOutsideQuotes returns the index of the first occurrence of sep in s
+// outside quoted values, or -1.
+func indexOutsideQuotes(s, sep string) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '\\':
+			i++
+		case strings.HasPrefix(s[i:], sep):
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,86 @@
+//go:build js && wasm
+
+package main
+
+import "testing"
+
+func TestXPathToQuery(t *testing.T) {
+	tests := []struct{ xpath, path string }{
+		{"/config/interfaces/interface/name", "config.interfaces.interface.name"},
+		{"//interface/@name", "**.interface.@name"},
+		{"/r/*/a:b/@*", "r.*.a:b.@*"},
+		{"/r/child::a/attribute::b", "r.a.@b"},
+		{"/r/./a/..", "r.a.parent()"},
+		{"/r/a/text()", "r.a.text()"},
+		{"/r/comment()", "r.comment()"},
+		{"/r/a[2]", "r.a[2]"},
+		{"/r/a[last()]", "r.a[last()]"},
+		{"/r/a[last()-1]", "r.a[last()-1]"},
+		{"/r/a[position() mod 2 = 1]", "r.a[position() mod 2 = 1]"},
+		{"/r/a[not(@b)]", "r.a[not(@b)]"},
+		{"/r/a[@b='v']", `r.a.#(@b=="v")#`},
+		{"/r/a[mtu>1500]/name", "r.a.#(mtu>1500)#.name"},
+		{"/r/a[contains(., 'x')]", `r.a.#(.%"*x*")#`},
+		{"/r/a[@b='1' and (c or d)]", `r.a.#(@b=="1" && (c || d))#`},
+		{"count(/r/a)", "r.a.#"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.xpath, func(t *testing.T) {
+			response := call(t, xpathToQuery, tt.xpath)
+			if response["path"] != tt.path {
+				t.Errorf("got %v, want path %q", response, tt.path)
+			}
+		})
+	}
+}
+
+func TestXPathToQueryRejectsUnsupported(t *testing.T) {
+	for _, xpath := range []string{"/r/a | /r/b", "/r/following::a", "/r/a[$v]", "/r/a[translate(., 'a', 'b')]", "/r/a[b+1=2]"} {
+		if response := call(t, xpathToQuery, xpath); response["code"] != codeInvalidPath {
+			t.Errorf("%s: got %v, want an invalidPath error", xpath, response)
+		}
+	}
+}
+
+func TestQueryToXPath(t *testing.T) {
+	tests := []struct{ path, xpath string }{
+		{"interfaces.interface.#(mtu>1500)#.name", "/interfaces/interface[mtu>1500]/name"},
+		{"r.a.@name", "/r/a/@name"},
+		{"r.*.@*", "/r/*/@*"},
+		{"r.*:b", "/r/*[local-name()='b']"},
+		{"**.name", "//name"},
+		{"r.a.parent()", "/r/a/.."},
+		{"r.a.%", "/r/a/text()"},
+		{"r.comment()", "/r/comment()"},
+		{"r.a.1", "/r/a[2]"},
+		{"r.a[last()-1]", "/r/a[last()-1]"},
+		{"r.a.#", "count(/r/a)"},
+		{"r.a.#(b==x)", "/r/a[b='x'][1]"},
+		{"r.a.#(@b==1 && (c!=2 || d))#", "/r/a[@b=1 and (c!=2 or d)]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			response := call(t, queryToXPath, tt.path)
+			if response["xpath"] != tt.xpath {
+				t.Errorf("got %v, want xpath %q", response, tt.xpath)
+			}
+		})
+	}
+}
+
+func TestQueryToXPathRejectsUntranslatable(t *testing.T) {
+	for _, path := range []string{"r.a|@reverse", "{r.a,r.b}", "r.a*", "r.#(b%x?y)#"} {
+		if response := call(t, queryToXPath, path); response["code"] != codeInvalidPath {
+			t.Errorf("%s: got %v, want an invalidPath error", path, response)
+		}
+	}
+}
+
+func TestXPathRoundTrip(t *testing.T) {
+	for _, path := range []string{"r.a.@b", "**.a.#(@b==\"1\")#.c", "r.a[last()]", "r.a.parent()"} {
+		xpath := call(t, queryToXPath, path)["xpath"].(string)
+		if back := call(t, xpathToQuery, xpath)["path"]; back != path {
+			t.Errorf("%s: translated to %s and back to %v", path, xpath, back)
+		}
+	}
+}
@@ -34,6 +34,14 @@ const EXAMPLES = {
 </catalog>`,
             path: "catalog.book.#",
             description: "Count the number of matching elements"
+        },
+        {
+            name: "Names Containing Dots",
+            xml: `<schema>
+  <version.info release="1.2.3">stable</version.info>
+</schema>`,
+            path: "schema.version\\.info.@release",
+            description: "Escape a literal dot in a name with a backslash"
         }
     ],
     arrays: [
@@ -54,8 +62,28 @@ const EXAMPLES = {
   <book><title>Second Book</title></book>
   <book><title>Last Book</title></book>
 </catalog>`,
-            path: "catalog.book.2.title",
-            description: "Access last element by index (third element = index 2)"
+            path: "catalog.book.-1.title",
+            description: "Negative indexes count from the end (-1 = last, -2 = second to last)"
+        },
+        {
+            name: "Positional Step",
+            xml: `<interfaces>
+  <interface><name>GigabitEthernet0/0</name></interface>
+  <interface><name>GigabitEthernet0/1</name></interface>
+  <interface><name>GigabitEthernet0/2</name></interface>
+</interfaces>`,
+            path: "interfaces.interface[2].name",
+            description: "XPath-style 1-based positions; [last()] and [last()-1] count from the end"
+        },
+        {
+            name: "All Matches",
+            xml: `<interfaces>
+  <interface><name>GigabitEthernet0/0</name></interface>
+  <interface><name>GigabitEthernet0/1</name></interface>
+  <interface><name>GigabitEthernet0/2</name></interface>
+</interfaces>`,
+            path: "interfaces.interface.#.name",
+            description: "A plain path returns the first match; # collects every match into an array"
         }
     ],
     wildcards: [
@@ -68,6 +96,18 @@ const EXAMPLES = {
             path: "catalog.*.title",
             description: "Match any element at one level"
         },
+        {
+            name: "All Direct Children",
+            xml: `<config>
+  <system>
+    <hostname>router1</hostname>
+    <domain>example.com</domain>
+    <timezone>UTC</timezone>
+  </system>
+</config>`,
+            path: "config.system.*",
+            description: "* matches every child exactly one level down (** matches at any depth)"
+        },
         {
             name: "Recursive Wildcard",
             xml: `<catalog>
@@ -78,6 +118,56 @@ const EXAMPLES = {
 </catalog>`,
             path: "catalog.**.price",
             description: "Match elements at any depth"
+        },
+        {
+            name: "Recursive Search from Root",
+            xml: `<config>
+  <system>
+    <hostname>core-rtr-01</hostname>
+  </system>
+</config>`,
+            path: "**.hostname",
+            description: "Find an element anywhere in the document without knowing its full path"
+        },
+        {
+            name: "Anchored Recursive Search",
+            xml: `<config>
+  <vrfs>
+    <vrf><interfaces><interface><name>Gi0/1</name></interface></interfaces></vrf>
+  </vrfs>
+  <interfaces>
+    <interface><name>Gi0/0</name></interface>
+  </interfaces>
+</config>`,
+            path: "config.**.interface.name",
+            description: "Search below config only; matches are returned in document order (depth-first)"
+        },
+        {
+            name: "Attribute Wildcard",
+            xml: `<interfaces>
+  <interface name="GigabitEthernet0/0" mtu="9000" shutdown="false">uplink</interface>
+</interfaces>`,
+            path: "interfaces.interface.@*",
+            description: "Collect every attribute value of an element in source order"
+        },
+        {
+            name: "Any Namespace",
+            xml: `<config xmlns:oc="http://openconfig.net/yang/interfaces">
+  <oc:interface><oc:name>Ethernet1</oc:name></oc:interface>
+  <interface><name>Ethernet2</name></interface>
+</config>`,
+            path: "config.*:interface.*:name",
+            description: "*:name matches a local name in any namespace or none; * alone matches any element"
+        },
+        {
+            name: "Attributes by Namespace URI",
+            xml: `<config xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
+        xmlns:i="http://www.w3.org/2001/XMLSchema-instance">
+  <value xsi:type="string">eth0</value>
+  <value i:type="int">42</value>
+</config>`,
+            path: "config.value.#.@Q{http://www\\.w3\\.org/2001/XMLSchema-instance}type",
+            description: "@Q{uri}local matches an attribute under any prefix bound to the URI; @* skips xmlns declarations, @xmlns:* lists them"
         }
     ],
     filters: [
@@ -110,6 +200,73 @@ const EXAMPLES = {
 </catalog>`,
             path: 'catalog.book.#(@id==active)#.title',
             description: "Filter books by attribute value"
+        },
+        {
+            name: "Filter by Numeric Threshold",
+            xml: `<interfaces>
+  <interface><name>GigabitEthernet0/0</name><mtu>9000</mtu></interface>
+  <interface><name>GigabitEthernet0/1</name><mtu>1500</mtu></interface>
+  <interface><name>Loopback0</name><mtu>1514</mtu></interface>
+</interfaces>`,
+            path: "interfaces.interface.#(mtu>1500)#.name",
+            description: "Numeric comparison against child text (>, <, >=, <=, ==, !=)"
+        },
+        {
+            name: "Filter by Inequality",
+            xml: `<interfaces>
+  <interface><name>GigabitEthernet0/0</name><enabled>true</enabled></interface>
+  <interface><name>GigabitEthernet0/1</name><enabled>false</enabled></interface>
+</interfaces>`,
+            path: "interfaces.interface.#(enabled!=true)#.name",
+            description: "Non-numeric values fall back to string comparison"
+        },
+        {
+            name: "Range by position()",
+            xml: `<log>
+  <day date="2024-05-01"><entry>boot</entry><entry>login</entry><entry>backup</entry></day>
+  <day date="2024-05-02"><entry>login</entry><entry>upgrade</entry></day>
+</log>`,
+            path: "log.day.entry[position()<=2]",
+            description: "position() counts matches per parent, so this is the first two entries of each day; position() mod 2 = 1 keeps odd ones"
+        },
+        {
+            name: "Has Attribute",
+            xml: `<interfaces>
+  <interface shutdown=""><name>GigabitEthernet0/0</name></interface>
+  <interface description="uplink"><name>GigabitEthernet0/1</name></interface>
+  <interface><name>Loopback0</name></interface>
+</interfaces>`,
+            path: "interfaces.interface[not(@shutdown)].name",
+            description: "[@name] keeps elements carrying the attribute, whatever its value, and [not(@name)] those without it; [@*] tests for any attribute"
+        },
+        {
+            name: "Filter with And / Or",
+            xml: `<interfaces>
+  <interface enabled="true"><name>GigabitEthernet0/0</name><mtu>9000</mtu></interface>
+  <interface enabled="false"><name>GigabitEthernet0/1</name><mtu>9000</mtu></interface>
+  <interface enabled="true"><name>Loopback0</name><mtu>1514</mtu></interface>
+</interfaces>`,
+            path: 'interfaces.interface.#(@enabled==true && (mtu>1500 || name%"Loop*"))#.name',
+            description: "&& binds more tightly than ||; parentheses group conditions (XPath and/or translate to these)"
+        },
+        {
+            name: "Filter by Own Text",
+            xml: `<interfaces>
+  <interface><name>GigabitEthernet0/0</name><mtu>9000</mtu></interface>
+  <interface><name>GigabitEthernet0/1</name><mtu>1500</mtu></interface>
+</interfaces>`,
+            path: 'interfaces.interface.name.#(.==GigabitEthernet0/1).parent().mtu',
+            description: ". compares the element's own text; parent() steps up to its container (like XPath ..)"
+        },
+        {
+            name: "Filter by Substring",
+            xml: `<interfaces>
+  <interface><name>GigabitEthernet0/0</name><description>Core uplink to R2</description></interface>
+  <interface><name>GigabitEthernet0/1</name><description>Access port</description></interface>
+  <interface><name>GigabitEthernet0/2</name></interface>
+</interfaces>`,
+            path: "interfaces.interface.#(description%'*uplink*')#.name",
+            description: "Pattern match on text content; elements without the child never match"
         }
     ],
     modifiers: [
@@ -133,6 +290,26 @@ const EXAMPLES = {
             path: "catalog.book.#.price|@sort",
             description: "Sort results in ascending order"
         },
+        {
+            name: "Sort Descending",
+            xml: `<interfaces>
+  <interface><name>Gi0/0</name><mtu>1500</mtu></interface>
+  <interface><name>Gi0/1</name><mtu>9000</mtu></interface>
+  <interface><name>Gi0/2</name><mtu>900</mtu></interface>
+</interfaces>`,
+            path: "interfaces.interface.#.mtu|@sort:num:desc",
+            description: "Stable sort variants: @sort:num (numeric), @sort:desc (text, descending), @sort:num:desc"
+        },
+        {
+            name: "Typed Fields",
+            xml: `<interface>
+  <name>Gi0/0</name>
+  <mtu> 9000 </mtu>
+  <enabled>yes</enabled>
+</interface>`,
+            path: "{name:interface.name,mtu:interface.mtu|@number,enabled:interface.enabled|@bool}",
+            description: "@number, @bool and @string coerce values; values that cannot be converted become null"
+        },
         {
             name: "Get First Element",
             xml: `<catalog>
@@ -152,6 +329,27 @@ const EXAMPLES = {
 </catalog>`,
             path: "catalog.book.#.title|@sort|@reverse",
             description: "Chain multiple modifiers (sort then reverse)"
+        },
+        {
+            name: "Child Names and Values",
+            xml: `<config>
+  <system>
+    <hostname>router1</hostname>
+    <domain>example.com</domain>
+    <timezone>UTC</timezone>
+  </system>
+</config>`,
+            path: "config.system|@keys",
+            description: "List child tag names (use @values for their text)"
+        },
+        {
+            name: "Flatten Nested Values",
+            xml: `<config>
+  <system><hostname>router1</hostname><domain>example.com</domain></system>
+  <ntp><server>10.0.0.1</server><server>10.0.0.2</server></ntp>
+</config>`,
+            path: "config.*|@values|@flatten",
+            description: "@values on each section gives nested arrays; @flatten merges one level (@flatten:deep merges all)"
         }
     ],
     advanced: [
@@ -168,6 +366,69 @@ const EXAMPLES = {
 </library>`,
             path: 'library.section.#(@name=="tech").book.#(price>40).title',
             description: "Nested filters with attribute matching"
+        },
+        {
+            name: "Select Container by Child Value",
+            xml: `<interfaces>
+  <interface><name>GigabitEthernet0/0</name><mtu>9000</mtu></interface>
+  <interface><name>GigabitEthernet0/1</name><mtu>1500</mtu></interface>
+</interfaces>`,
+            path: "interfaces.interface.#(name==GigabitEthernet0/0).mtu",
+            description: "A filter matches the containing element, so no parent step is needed to reach sibling fields"
+        },
+        {
+            name: "Read Comments",
+            xml: `<config>
+  <!-- generated-by: provisioning v2 -->
+  <hostname>core-rtr-01</hostname>
+</config>`,
+            path: "config.comment()",
+            description: "Read comment children of an element (an array when there are several)"
+        },
+        {
+            name: "Comment Annotations",
+            xml: `<config>
+  <!-- uplink to core -->
+  <interface><name>Gi0/0</name></interface>
+  <!-- spare -->
+  <!-- do not enable -->
+  <interface><name>Gi0/1</name></interface>
+</config>`,
+            path: "config.comment()[2].following-sibling().name",
+            description: "Positions on comment() count comments in document order; following-sibling() reaches the element a comment annotates, and interface.1.preceding-comment() goes the other way"
+        },
+        {
+            name: "Direct Text Nodes",
+            xml: `<doc>
+  <p>Hello <b>world</b>!</p>
+</doc>`,
+            path: "doc.p.text()",
+            description: "Read only the text directly inside an element, one entry per text node"
+        },
+        {
+            name: "DOCTYPE Entities",
+            xml: `<!DOCTYPE config [
+  <!ENTITY site "Branch Office">
+  <!-- the internal subset may contain ] and > in comments and literals -->
+  <!ATTLIST device role CDATA "access">
+]>
+<config>
+  <device>
+    <hostname>rtr-01</hostname>
+    <location>&site;</location>
+  </device>
+</config>`,
+            path: "config.device.location",
+            description: "Entities declared in the internal DTD subset are expanded in values"
+        },
+        {
+            name: "Multipath Summary",
+            xml: `<interfaces>
+  <interface><name>GigabitEthernet0/0</name><mtu>9000</mtu></interface>
+  <interface><name>GigabitEthernet0/1</name><mtu>1500</mtu></interface>
+</interfaces>`,
+            path: "{names:interfaces.interface.#.name,count:interfaces.interface.#}",
+            description: "Collect several paths into one JSON object; missing paths become null"
         }
     ]
 };